@@ -11,6 +11,10 @@ type Command interface {
 	Help() string
 	Execute(ctx *Context, args []string) error
 	Check(ctx *Context, args []string) error
+	// ReadOnly reports whether the command only reads the database. It lets
+	// an open begin/commit/rollback session (see Context.Tx) decide whether
+	// a command may run outside of one.
+	ReadOnly() bool
 }
 
 type Usage interface {
@@ -102,25 +106,38 @@ func (c *helpCommand) Execute(ctx *Context, args []string) error {
 			if v, ok := e.(Usage); ok {
 				ctx.Printf(v.Usage() + "\n")
 			} else {
-				fmt.Fprintf(ctx.Output(), "%s    %s\n", strings.Join(e.Alias(), ", "), e.Help())
+				fmt.Fprintf(ctx.Output(), "%s    %s\n", strings.Join(e.Alias(), ", "), helpLine(e))
 			}
 		}
 		return nil
 	}
 	for _, v := range c.cmd.cmds {
-		fmt.Fprintf(ctx.Output(), "%-24s %s\n", strings.Join(v.Alias(), ", "), v.Help())
+		fmt.Fprintf(ctx.Output(), "%-24s %s\n", strings.Join(v.Alias(), ", "), helpLine(v))
 	}
 	return nil
 }
 
+// helpLine appends a "(read-only)" marker for commands that never write, so
+// `help` also documents which commands are always safe to run against a
+// database that's open for reading only.
+func helpLine(e Command) string {
+	if e.ReadOnly() {
+		return e.Help() + " (read-only)"
+	}
+	return e.Help()
+}
+
 func (c *helpCommand) Help() string {
 	return "Print command help text. Specific a command name for more information about it."
 }
 
+func (c *helpCommand) ReadOnly() bool { return true }
+
 type exitCommand struct{}
 
 func (exitCommand) Alias() []string { return []string{"exit", "q"} }
 func (exitCommand) Help() string    { return "Exit." }
+func (exitCommand) ReadOnly() bool  { return true }
 func (exitCommand) Check(ctx *Context, args []string) error {
 	return NewValidats().NumArgs(0).Finish()(ctx, args)
 }
@@ -25,30 +25,78 @@ func init() {
 }
 
 type Context struct {
-	line    *liner.State
-	history string
-	prompt  string
-	err     error
-	output  io.Writer
+	line       *liner.State
+	history    string
+	prompt     string
+	basePrompt string
+	err        error
+	output     io.Writer
 
 	command string
+	tx      Tx
+	encoder Encoder
 }
 
 func NewContext() *Context {
 	c := &Context{
-		line:    liner.NewLiner(),
-		history: HistoryFile,
-		prompt:  Prompt,
-		output:  os.Stdout,
+		line:       liner.NewLiner(),
+		history:    HistoryFile,
+		prompt:     Prompt,
+		basePrompt: Prompt,
+		output:     os.Stdout,
+		encoder:    autoEncoder{},
 	}
 	c.line.SetCtrlCAborts(true)
 	return c
 }
 
+// Close discards any dangling transaction left open by a begin command,
+// then closes the underlying liner session.
 func (ctx *Context) Close() error {
+	if ctx.tx != nil {
+		_ = ctx.tx.Rollback()
+		ctx.tx = nil
+	}
 	return ctx.line.Close()
 }
 
+// Tx returns the transaction opened by an in-progress begin/commit/rollback
+// session, or nil if none is open.
+func (ctx *Context) Tx() Tx {
+	return ctx.tx
+}
+
+// SetTx sets or clears the open transaction, refreshing the prompt to show
+// whether a session is active.
+func (ctx *Context) SetTx(tx Tx) {
+	ctx.tx = tx
+	ctx.refreshPrompt()
+}
+
+// Encoder returns the Encoder used to render and parse key/value bytes, as
+// selected by the `set encoding` command.
+func (ctx *Context) Encoder() Encoder {
+	return ctx.encoder
+}
+
+// SetEncoding switches the session's Encoder.
+func (ctx *Context) SetEncoding(e Encoding) error {
+	enc, err := NewEncoder(e)
+	if err != nil {
+		return err
+	}
+	ctx.encoder = enc
+	return nil
+}
+
+func (ctx *Context) refreshPrompt() {
+	if ctx.tx != nil {
+		ctx.prompt = ctx.basePrompt + "* "
+	} else {
+		ctx.prompt = ctx.basePrompt
+	}
+}
+
 func (ctx *Context) Command() string {
 	return ctx.command
 }
@@ -91,7 +139,8 @@ func (ctx *Context) SetHistory(f string) {
 }
 
 func (ctx *Context) SetPrompt(f string) {
-	ctx.prompt = f
+	ctx.basePrompt = f
+	ctx.refreshPrompt()
 }
 
 func (ctx *Context) Output() io.Writer {
@@ -6,18 +6,26 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/tomlazar/table"
 	bolt "go.etcd.io/bbolt"
 )
 
-func NewRegisterWithDB(db *bolt.DB) Register {
+// NewRegisterWithDB registers the core stat/get/put/del/bucket commands
+// against db. ix is the index maintained by the index/reindex/search
+// commands (see RegisterIndexCommands); it may be nil, in which case
+// put/del/rename simply don't maintain any index. Passing the same *Indexer
+// to both is what lets write commands keep an index in sync with the data
+// the index/search commands report on.
+func NewRegisterWithDB(db DB, ix *Indexer) Register {
 	r := NewRegister()
 	r.Register(&dbCommand{
 		db:        db,
 		alias:     []string{"stat", "st"},
 		help:      "Print database basic information",
 		validates: NewValidats().NumArgs(0),
+		readOnly:  true,
 		executor:  commandStat,
 	})
 	r.Register(&dbCommand{
@@ -25,6 +33,7 @@ func NewRegisterWithDB(db *bolt.DB) Register {
 		alias:     []string{"get", "g"},
 		help:      "Get key-value pairs",
 		validates: NewValidats().MinArgs(2).MaxArgs(1024),
+		readOnly:  true,
 		executor:  commandGet,
 	})
 	r.Register(&dbCommand{
@@ -32,6 +41,7 @@ func NewRegisterWithDB(db *bolt.DB) Register {
 		alias:     []string{"buckets", "b"},
 		help:      "List all buckets",
 		validates: NewValidats().NumArgs(0),
+		readOnly:  true,
 		executor:  commandListBucket,
 	})
 	r.Register(&dbCommand{
@@ -39,32 +49,104 @@ func NewRegisterWithDB(db *bolt.DB) Register {
 		alias:     []string{"keys", "k"},
 		help:      "List all buckets",
 		validates: NewValidats().MinArgs(1).MaxArgs(2).Choices(1, []string{"withvalue"}),
+		readOnly:  true,
 		executor:  commandListBucketKeys,
 	})
+	r.Register(&dbCommand{
+		db:        db,
+		ix:        ix,
+		alias:     []string{"put"},
+		help:      "Put a key-value pair: put <bucket...> <key> <value>",
+		validates: NewValidats().MinArgs(3).MaxArgs(1024),
+		executor:  commandPut,
+	})
+	r.Register(&dbCommand{
+		db:        db,
+		ix:        ix,
+		alias:     []string{"del"},
+		help:      "Delete a key: del <bucket...> <key>",
+		validates: NewValidats().MinArgs(2).MaxArgs(1024),
+		executor:  commandDel,
+	})
+	r.Register(&dbCommand{
+		db:        db,
+		alias:     []string{"mkbucket"},
+		help:      "Create a (possibly nested) bucket: mkbucket <bucket...>",
+		validates: NewValidats().MinArgs(1).MaxArgs(1024),
+		executor:  commandMkBucket,
+	})
+	r.Register(&dbCommand{
+		db:        db,
+		alias:     []string{"rmbucket"},
+		help:      "Remove a (possibly nested) bucket: rmbucket <bucket...>",
+		validates: NewValidats().MinArgs(1).MaxArgs(1024),
+		executor:  commandRmBucket,
+	})
+	r.Register(&dbCommand{
+		db:        db,
+		ix:        ix,
+		alias:     []string{"rename"},
+		help:      "Rename a key in place: rename <bucket...> <oldkey> <newkey>",
+		validates: NewValidats().MinArgs(3).MaxArgs(1024),
+		executor:  commandRename,
+	})
+	r.Register(&beginCommand{db: db})
+	r.Register(commitCommand{})
+	r.Register(rollbackCommand{})
+	r.Register(setCommand{})
 	return r
 }
 
+// bucketPath decodes a slice of escaped bucket-name arguments into the
+// nested bucket path used by DB operations.
+func bucketPath(args []string) []string {
+	path := make([]string, len(args))
+	for i, a := range args {
+		path[i] = string(stringToBytes(a))
+	}
+	return path
+}
+
+// safeBytesToString renders b as a printable string: printable ASCII passes
+// through unchanged, and every other byte - including the backslash itself,
+// which would otherwise be ambiguous with an escape - is rendered as a
+// two-digit \xHH escape. Unlike the %q-based escaping this replaced, every
+// one of the 256 byte values round-trips through stringToBytes.
 func safeBytesToString(b []byte) string {
-	a := fmt.Sprintf("%q", b)
-	r := strings.NewReplacer("\n", "\\x0a", "\r", "\\x0d", "\\\"", "\"", "\"", "")
-	return r.Replace(a)
+	var sb strings.Builder
+	for _, c := range b {
+		switch {
+		case c == '\\':
+			sb.WriteString(`\\`)
+		case c >= 0x20 && c < 0x7f:
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, `\x%02x`, c)
+		}
+	}
+	return sb.String()
 }
 
-var replaceRE = regexp.MustCompile(`\\x[a-z0-9]{2}`)
+var replaceRE = regexp.MustCompile(`\\x[0-9a-f]{2}|\\\\`)
 
 func stringToBytes(s string) []byte {
 	return replaceRE.ReplaceAllFunc([]byte(s), func(b []byte) []byte {
+		if len(b) == 2 {
+			return []byte{'\\'}
+		}
 		a, _ := strconv.ParseUint(string(b[2:]), 16, 8)
 		return []byte{byte(a)}
 	})
 }
 
 type dbCommand struct {
-	db        *bolt.DB
+	db        DB
+	ix        *Indexer
 	help      string
 	alias     []string
 	validates Validates
-	executor  func(db *bolt.DB, ctx *Context, args []string) error
+	readOnly  bool
+	executor  func(db DB, ix *Indexer, ctx *Context, args []string) error
 }
 
 func (g *dbCommand) Alias() []string {
@@ -75,12 +157,23 @@ func (g *dbCommand) Help() string {
 	return g.help
 }
 
+func (g *dbCommand) ReadOnly() bool {
+	return g.readOnly
+}
+
 func (g *dbCommand) Check(ctx *Context, args []string) error {
 	return g.validates.Finish()(ctx, args)
 }
 
+// Execute runs the command against ctx's open transaction if one exists
+// (a begin/commit/rollback session), or against g.db directly otherwise,
+// in which case each DB method opens its own db.View/db.Update.
 func (g *dbCommand) Execute(ctx *Context, args []string) error {
-	return g.executor(g.db, ctx, args)
+	db := g.db
+	if tx := ctx.Tx(); tx != nil {
+		db = tx
+	}
+	return g.executor(db, g.ix, ctx, args)
 }
 
 const (
@@ -88,59 +181,258 @@ const (
 	askContinueSize = 32
 )
 
-func commandStat(db *bolt.DB, ctx *Context, args []string) error {
+func commandStat(db DB, ix *Indexer, ctx *Context, args []string) error {
 	var tab = table.Table{
 		Headers: []string{"keys", "bucket", "max-btree-depth"},
 	}
-	var total, buckets, maxDepth int64
-	err := db.View(func(tx *bolt.Tx) error {
-		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
-			s := b.Stats()
-			total += int64(s.KeyN)
-			buckets++
-			if s.Depth > int(maxDepth) {
-				maxDepth = int64(s.Depth)
-			}
-			return nil
-		})
-	})
+	st, err := db.Stat()
 	if err != nil {
 		return err
 	}
 	tab.Rows = append(tab.Rows, []string{
-		fmt.Sprintf("%d", total),
-		fmt.Sprintf("%d", buckets),
-		fmt.Sprintf("%d", maxDepth),
+		fmt.Sprintf("%d", st.TotalKeys),
+		fmt.Sprintf("%d", st.Buckets),
+		fmt.Sprintf("%d", st.MaxDepth),
 	})
 	cfg := table.DefaultConfig()
 	cfg.ShowIndex = false
 	return tab.WriteTable(ctx.Output(), cfg)
 }
 
-func commandGet(db *bolt.DB, ctx *Context, args []string) error {
-	return db.View(func(tx *bolt.Tx) error {
-		bu := tx.Bucket(stringToBytes(args[0]))
-		if bu == nil {
-			ctx.Printf("err: bucket not found\n")
-			return nil
-		}
-		if len(args)-2 > 0 {
-			for _, b := range args[1 : len(args)-1] {
-				bu = bu.Bucket(stringToBytes(b))
-				if bu == nil {
-					ctx.Printf("err: bucket %s not found\n", b)
-					return nil
-				}
-			}
+func commandGet(db DB, ix *Indexer, ctx *Context, args []string) error {
+	bucket := bucketPath(args[:len(args)-1])
+	key, err := ctx.Encoder().Decode(args[len(args)-1])
+	if err != nil {
+		return err
+	}
+	v, found, err := db.Get(bucket, key)
+	if err != nil {
+		return err
+	}
+	if !found {
+		ctx.Printf("err: key-value not found\n")
+		return nil
+	}
+	ctx.Printf("%s", ctx.Encoder().Encode(v))
+	return nil
+}
+
+func commandPut(db DB, ix *Indexer, ctx *Context, args []string) error {
+	bucket := bucketPath(args[:len(args)-2])
+	key, err := ctx.Encoder().Decode(args[len(args)-2])
+	if err != nil {
+		return err
+	}
+	value, err := ctx.Encoder().Decode(args[len(args)-1])
+	if err != nil {
+		return err
+	}
+	if err := db.Put(bucket, key, value); err != nil {
+		return err
+	}
+	if err := notifyIndexPut(ix, bucket, key, value); err != nil {
+		return err
+	}
+	ctx.Printf("OK\n")
+	return nil
+}
+
+func commandDel(db DB, ix *Indexer, ctx *Context, args []string) error {
+	bucket := bucketPath(args[:len(args)-1])
+	key, err := ctx.Encoder().Decode(args[len(args)-1])
+	if err != nil {
+		return err
+	}
+	if err := db.Delete(bucket, key); err != nil {
+		return err
+	}
+	if err := notifyIndexDelete(ix, bucket, key); err != nil {
+		return err
+	}
+	ctx.Printf("OK\n")
+	return nil
+}
+
+func commandMkBucket(db DB, _ *Indexer, ctx *Context, args []string) error {
+	if err := db.CreateBucket(bucketPath(args)); err != nil {
+		return err
+	}
+	ctx.Printf("OK\n")
+	return nil
+}
+
+func commandRmBucket(db DB, _ *Indexer, ctx *Context, args []string) error {
+	if err := db.DeleteBucket(bucketPath(args)); err != nil {
+		return err
+	}
+	ctx.Printf("OK\n")
+	return nil
+}
+
+// renameInDB does the actual Get+Put+Delete of commandRename against rdb,
+// reporting whether oldKey was found.
+func renameInDB(rdb DB, ix *Indexer, bucket []string, oldKey, newKey []byte) (bool, error) {
+	v, found, err := rdb.Get(bucket, oldKey)
+	if err != nil || !found {
+		return found, err
+	}
+	if err := rdb.Put(bucket, newKey, v); err != nil {
+		return false, err
+	}
+	if err := notifyIndexPut(ix, bucket, newKey, v); err != nil {
+		return false, err
+	}
+	if err := rdb.Delete(bucket, oldKey); err != nil {
+		return false, err
+	}
+	return true, notifyIndexDelete(ix, bucket, oldKey)
+}
+
+func commandRename(db DB, ix *Indexer, ctx *Context, args []string) error {
+	bucket := bucketPath(args[:len(args)-2])
+	oldKey, err := ctx.Encoder().Decode(args[len(args)-2])
+	if err != nil {
+		return err
+	}
+	newKey, err := ctx.Encoder().Decode(args[len(args)-1])
+	if err != nil {
+		return err
+	}
+
+	var found bool
+	if tx := ctx.Tx(); tx != nil {
+		// Inside an open begin/commit session, db is already that shared
+		// tx; its atomicity is governed by the session's own commit/rollback.
+		found, err = renameInDB(db, ix, bucket, oldKey, newKey)
+	} else {
+		// Outside a session, Get+Put+Delete need a transaction of their
+		// own so a crash or error between the two writes can't leave both
+		// the old and new keys behind.
+		var wtx Tx
+		wtx, err = db.Begin()
+		if err != nil {
+			return err
 		}
-		v := bu.Get(stringToBytes(args[len(args)-1]))
-		if v == nil {
-			ctx.Printf("err: key-value not found\n")
-		} else {
-			ctx.Printf(safeBytesToString(v))
+		found, err = renameInDB(wtx, ix, bucket, oldKey, newKey)
+		if err != nil {
+			wtx.Rollback() // nolint:errcheck
+			return err
 		}
+		err = wtx.Commit()
+	}
+	if err != nil {
+		return err
+	}
+	if !found {
+		ctx.Printf("err: key-value not found\n")
 		return nil
-	})
+	}
+	ctx.Printf("OK\n")
+	return nil
+}
+
+// beginCommand opens a long-lived transaction on ctx; subsequent write
+// commands apply to it until commit or rollback.
+type beginCommand struct {
+	db DB
+}
+
+func (c *beginCommand) Alias() []string { return []string{"begin"} }
+
+func (c *beginCommand) Help() string {
+	return "Begin an interactive transaction; writes apply to it until commit/rollback"
+}
+
+func (c *beginCommand) ReadOnly() bool { return false }
+
+func (c *beginCommand) Check(ctx *Context, args []string) error {
+	return NewValidats().NumArgs(0).Finish()(ctx, args)
+}
+
+func (c *beginCommand) Execute(ctx *Context, args []string) error {
+	if ctx.Tx() != nil {
+		ctx.Printf("err: a transaction is already open\n")
+		return nil
+	}
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+	ctx.SetTx(tx)
+	ctx.Printf("OK\n")
+	return nil
+}
+
+type commitCommand struct{}
+
+func (commitCommand) Alias() []string { return []string{"commit"} }
+func (commitCommand) Help() string    { return "Commit the open transaction" }
+func (commitCommand) ReadOnly() bool  { return false }
+func (commitCommand) Check(ctx *Context, args []string) error {
+	return NewValidats().NumArgs(0).Finish()(ctx, args)
+}
+
+func (commitCommand) Execute(ctx *Context, args []string) error {
+	tx := ctx.Tx()
+	if tx == nil {
+		ctx.Printf("err: no transaction is open\n")
+		return nil
+	}
+	ctx.SetTx(nil)
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	ctx.Printf("OK\n")
+	return nil
+}
+
+type rollbackCommand struct{}
+
+func (rollbackCommand) Alias() []string { return []string{"rollback"} }
+func (rollbackCommand) Help() string    { return "Discard the open transaction" }
+func (rollbackCommand) ReadOnly() bool  { return false }
+func (rollbackCommand) Check(ctx *Context, args []string) error {
+	return NewValidats().NumArgs(0).Finish()(ctx, args)
+}
+
+func (rollbackCommand) Execute(ctx *Context, args []string) error {
+	tx := ctx.Tx()
+	if tx == nil {
+		ctx.Printf("err: no transaction is open\n")
+		return nil
+	}
+	ctx.SetTx(nil)
+	if err := tx.Rollback(); err != nil {
+		return err
+	}
+	ctx.Printf("OK\n")
+	return nil
+}
+
+// setCommand changes a session setting; currently only the Encoder used to
+// render and parse key/value bytes.
+type setCommand struct{}
+
+func (setCommand) Alias() []string { return []string{"set"} }
+
+func (setCommand) Help() string {
+	return "Change a session setting: 'set encoding <auto|hex|base64|raw|utf8>'"
+}
+
+func (setCommand) ReadOnly() bool { return true }
+
+func (setCommand) Check(ctx *Context, args []string) error {
+	return NewValidats().NumArgs(2).Choices(0, []string{"encoding"}).
+		Choices(1, []string{string(EncodingAuto), string(EncodingHex), string(EncodingBase64), string(EncodingRaw), string(EncodingUTF8)}).
+		Finish()(ctx, args)
+}
+
+func (setCommand) Execute(ctx *Context, args []string) error {
+	if err := ctx.SetEncoding(Encoding(args[1])); err != nil {
+		return err
+	}
+	ctx.Printf("OK\n")
+	return nil
 }
 
 func askContinue(ctx *Context) bool {
@@ -202,29 +494,27 @@ func newTablePrinter(headers []string) *tablePrinter {
 
 var errExit = errors.New("exit")
 
-func commandListBucket(db *bolt.DB, ctx *Context, args []string) error {
+func commandListBucket(db DB, _ *Indexer, ctx *Context, args []string) error {
 	tl := newTablePrinter([]string{"bucket", "keys", "depth"})
-	err := db.View(func(tx *bolt.Tx) error {
-		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
-			s := b.Stats()
-			if !tl.add(ctx, []string{
-				safeBytesToString(name),
-				fmt.Sprintf("%d", s.KeyN),
-				fmt.Sprintf("%d", s.Depth),
-			}) {
-				return errExit
-			}
-			return nil
-		})
-	})
-	tl.out(ctx)
-	if err == errExit { // nolint:errorlint
-		return nil
+	buckets, err := db.ListBuckets()
+	if err != nil {
+		return err
 	}
-	return err
+	enc := ctx.Encoder()
+	for _, b := range buckets {
+		if !tl.add(ctx, []string{
+			enc.Encode([]byte(b.Name)),
+			fmt.Sprintf("%d", b.Keys),
+			fmt.Sprintf("%d", b.Depth),
+		}) {
+			break
+		}
+	}
+	tl.out(ctx)
+	return nil
 }
 
-func commandListBucketKeys(db *bolt.DB, ctx *Context, args []string) error {
+func commandListBucketKeys(db DB, _ *Indexer, ctx *Context, args []string) error {
 	hasValue := len(args) > 1
 	var tl *tablePrinter
 	if hasValue {
@@ -233,26 +523,380 @@ func commandListBucketKeys(db *bolt.DB, ctx *Context, args []string) error {
 		tl = newTablePrinter([]string{"key"})
 	}
 
-	err := db.View(func(tx *bolt.Tx) error {
-		bu := tx.Bucket(stringToBytes(args[0]))
+	enc := ctx.Encoder()
+	err := db.ListKeys([]string{string(stringToBytes(args[0]))}, hasValue, func(k, v []byte) error {
+		r := []string{enc.Encode(k)}
+		if hasValue {
+			r = append(r, enc.Encode(v))
+		}
+		if !tl.add(ctx, r) {
+			return errExit
+		}
+		return nil
+	})
+	tl.out(ctx)
+	if err == errExit { // nolint:errorlint
+		return nil
+	}
+	return err
+}
+
+// localDB is a DB backed directly by an on-disk *bbolt.DB. Every call opens
+// its own read or write transaction; NewTx is used instead when several
+// operations need to share one (e.g. an interactive begin/commit session).
+//
+// bbolt's writer lock is a plain per-process mutex: calling db.Update or
+// db.Begin(true) a second time on the same goroutine while a writer tx is
+// already held self-deadlocks instead of erroring. sessionOpen guards every
+// write path below against that, so a command that forgets to route through
+// ctx.Tx() (see dbCommand.Execute) fails with a clear error instead of
+// hanging the whole REPL.
+type localDB struct {
+	db *bolt.DB
+
+	mu          sync.Mutex
+	sessionOpen bool
+}
+
+var errSessionOpen = errors.New("a transaction is open; use it or finish it with commit/rollback first")
+
+func (l *localDB) checkNoSession() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.sessionOpen {
+		return errSessionOpen
+	}
+	return nil
+}
+
+func (l *localDB) endSession() {
+	l.mu.Lock()
+	l.sessionOpen = false
+	l.mu.Unlock()
+}
+
+// NewLocalDB wraps db as a DB.
+func NewLocalDB(db *bolt.DB) DB {
+	return &localDB{db: db}
+}
+
+func openBucket(tx *bolt.Tx, path []string, create bool) (*bolt.Bucket, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("bucket path is required")
+	}
+	var bu *bolt.Bucket
+	if create {
+		var err error
+		bu, err = tx.CreateBucketIfNotExists([]byte(path[0]))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		bu = tx.Bucket([]byte(path[0]))
 		if bu == nil {
-			ctx.Printf("err: bucket not found")
-			return nil
+			return nil, nil
 		}
-		return bu.ForEach(func(k, v []byte) error {
-			r := []string{safeBytesToString(v)}
-			if hasValue {
-				r = append(r, safeBytesToString(v))
+	}
+	for _, name := range path[1:] {
+		if create {
+			var err error
+			bu, err = bu.CreateBucketIfNotExists([]byte(name))
+			if err != nil {
+				return nil, err
 			}
-			if !tl.add(ctx, r) {
-				return errExit
+		} else {
+			bu = bu.Bucket([]byte(name))
+			if bu == nil {
+				return nil, nil
 			}
+		}
+	}
+	return bu, nil
+}
+
+// subBucketNamesFromTx lists the names of buckets nested directly under
+// path, or the top-level bucket names if path is empty.
+func subBucketNamesFromTx(tx *bolt.Tx, path []string) ([]string, error) {
+	if len(path) == 0 {
+		var names []string
+		err := tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			names = append(names, string(name))
 			return nil
 		})
+		return names, err
+	}
+	bu, err := openBucket(tx, path, false)
+	if err != nil || bu == nil {
+		return nil, err
+	}
+	var names []string
+	err = bu.ForEach(func(k, v []byte) error {
+		if v == nil {
+			names = append(names, string(k))
+		}
+		return nil
 	})
-	tl.out(ctx)
-	if err == errExit { // nolint:errorlint
+	return names, err
+}
+
+func statFromTx(tx *bolt.Tx) DBStat {
+	var st DBStat
+	_ = tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+		s := b.Stats()
+		st.TotalKeys += int64(s.KeyN)
+		st.Buckets++
+		if int64(s.Depth) > st.MaxDepth {
+			st.MaxDepth = int64(s.Depth)
+		}
+		return nil
+	})
+	return st
+}
+
+func listBucketsFromTx(tx *bolt.Tx) []BucketStat {
+	var out []BucketStat
+	_ = tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+		s := b.Stats()
+		out = append(out, BucketStat{Name: string(name), Keys: int64(s.KeyN), Depth: int64(s.Depth)})
 		return nil
+	})
+	return out
+}
+
+func listKeysFromTx(tx *bolt.Tx, bucket []string, withValue bool, fn func(k, v []byte) error) error {
+	bu, err := openBucket(tx, bucket, false)
+	if err != nil || bu == nil {
+		return err
+	}
+	return bu.ForEach(func(k, v []byte) error {
+		if withValue {
+			return fn(k, v)
+		}
+		return fn(k, nil)
+	})
+}
+
+func getFromTx(tx *bolt.Tx, bucket []string, key []byte) ([]byte, bool, error) {
+	bu, err := openBucket(tx, bucket, false)
+	if err != nil || bu == nil {
+		return nil, false, err
+	}
+	v := bu.Get(key)
+	if v == nil {
+		return nil, false, nil
+	}
+	return append([]byte(nil), v...), true, nil
+}
+
+func putInTx(tx *bolt.Tx, bucket []string, key, value []byte) error {
+	bu, err := openBucket(tx, bucket, true)
+	if err != nil {
+		return err
+	}
+	return bu.Put(key, value)
+}
+
+func deleteInTx(tx *bolt.Tx, bucket []string, key []byte) error {
+	bu, err := openBucket(tx, bucket, false)
+	if err != nil || bu == nil {
+		return err
 	}
+	return bu.Delete(key)
+}
+
+func createBucketInTx(tx *bolt.Tx, bucket []string) error {
+	_, err := openBucket(tx, bucket, true)
+	return err
+}
+
+func deleteBucketInTx(tx *bolt.Tx, bucket []string) error {
+	if len(bucket) == 1 {
+		return tx.DeleteBucket([]byte(bucket[0]))
+	}
+	parent, err := openBucket(tx, bucket[:len(bucket)-1], false)
+	if err != nil || parent == nil {
+		return err
+	}
+	return parent.DeleteBucket([]byte(bucket[len(bucket)-1]))
+}
+
+func (l *localDB) Stat() (DBStat, error) {
+	var st DBStat
+	err := l.db.View(func(tx *bolt.Tx) error {
+		st = statFromTx(tx)
+		return nil
+	})
+	return st, err
+}
+
+func (l *localDB) ListBuckets() ([]BucketStat, error) {
+	var out []BucketStat
+	err := l.db.View(func(tx *bolt.Tx) error {
+		out = listBucketsFromTx(tx)
+		return nil
+	})
+	return out, err
+}
+
+func (l *localDB) ListKeys(bucket []string, withValue bool, fn func(k, v []byte) error) error {
+	return l.db.View(func(tx *bolt.Tx) error {
+		return listKeysFromTx(tx, bucket, withValue, fn)
+	})
+}
+
+func (l *localDB) Get(bucket []string, key []byte) ([]byte, bool, error) {
+	var value []byte
+	var found bool
+	err := l.db.View(func(tx *bolt.Tx) error {
+		var err error
+		value, found, err = getFromTx(tx, bucket, key)
+		return err
+	})
+	return value, found, err
+}
+
+func (l *localDB) Put(bucket []string, key, value []byte) error {
+	if err := l.checkNoSession(); err != nil {
+		return err
+	}
+	return l.db.Update(func(tx *bolt.Tx) error {
+		return putInTx(tx, bucket, key, value)
+	})
+}
+
+func (l *localDB) Delete(bucket []string, key []byte) error {
+	if err := l.checkNoSession(); err != nil {
+		return err
+	}
+	return l.db.Update(func(tx *bolt.Tx) error {
+		return deleteInTx(tx, bucket, key)
+	})
+}
+
+func (l *localDB) CreateBucket(bucket []string) error {
+	if err := l.checkNoSession(); err != nil {
+		return err
+	}
+	return l.db.Update(func(tx *bolt.Tx) error {
+		return createBucketInTx(tx, bucket)
+	})
+}
+
+func (l *localDB) DeleteBucket(bucket []string) error {
+	if err := l.checkNoSession(); err != nil {
+		return err
+	}
+	return l.db.Update(func(tx *bolt.Tx) error {
+		return deleteBucketInTx(tx, bucket)
+	})
+}
+
+func (l *localDB) SubBuckets(bucket []string) ([]string, error) {
+	var names []string
+	err := l.db.View(func(tx *bolt.Tx) error {
+		var err error
+		names, err = subBucketNamesFromTx(tx, bucket)
+		return err
+	})
+	return names, err
+}
+
+// BatchUpdate applies muts in a single db.Update, so a multi-row writer
+// (import, load) gets one atomic transaction instead of one per mutation.
+func (l *localDB) BatchUpdate(muts []Mutation) error {
+	if err := l.checkNoSession(); err != nil {
+		return err
+	}
+	return l.db.Update(func(tx *bolt.Tx) error {
+		return applyMutations(tx, muts)
+	})
+}
+
+func applyMutations(tx *bolt.Tx, muts []Mutation) error {
+	for _, m := range muts {
+		if m.Delete {
+			if err := deleteInTx(tx, m.Bucket, m.Key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := putInTx(tx, m.Bucket, m.Key, m.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Begin opens a long-lived, writable bolt transaction for an interactive
+// begin/commit/rollback session.
+func (l *localDB) Begin() (Tx, error) {
+	l.mu.Lock()
+	if l.sessionOpen {
+		l.mu.Unlock()
+		return nil, errors.New("a transaction is already open")
+	}
+	l.sessionOpen = true
+	l.mu.Unlock()
+	tx, err := l.db.Begin(true)
+	if err != nil {
+		l.endSession()
+		return nil, err
+	}
+	return &localTx{tx: tx, db: l}, nil
+}
+
+// localTx is a Tx backed by a single long-lived *bbolt.Tx, shared by every
+// operation issued while the session is open.
+type localTx struct {
+	tx *bolt.Tx
+	db *localDB
+}
+
+func (t *localTx) Stat() (DBStat, error) { return statFromTx(t.tx), nil }
+
+func (t *localTx) ListBuckets() ([]BucketStat, error) { return listBucketsFromTx(t.tx), nil }
+
+func (t *localTx) ListKeys(bucket []string, withValue bool, fn func(k, v []byte) error) error {
+	return listKeysFromTx(t.tx, bucket, withValue, fn)
+}
+
+func (t *localTx) Get(bucket []string, key []byte) ([]byte, bool, error) {
+	return getFromTx(t.tx, bucket, key)
+}
+
+func (t *localTx) Put(bucket []string, key, value []byte) error {
+	return putInTx(t.tx, bucket, key, value)
+}
+
+func (t *localTx) Delete(bucket []string, key []byte) error {
+	return deleteInTx(t.tx, bucket, key)
+}
+
+func (t *localTx) CreateBucket(bucket []string) error { return createBucketInTx(t.tx, bucket) }
+
+func (t *localTx) DeleteBucket(bucket []string) error { return deleteBucketInTx(t.tx, bucket) }
+
+func (t *localTx) SubBuckets(bucket []string) ([]string, error) {
+	return subBucketNamesFromTx(t.tx, bucket)
+}
+
+// BatchUpdate applies muts against the transaction this session already
+// holds; it isn't a separate atomic unit, since commit/rollback governs the
+// whole session.
+func (t *localTx) BatchUpdate(muts []Mutation) error { return applyMutations(t.tx, muts) }
+
+func (t *localTx) Begin() (Tx, error) {
+	return nil, fmt.Errorf("a transaction is already open")
+}
+
+func (t *localTx) Commit() error {
+	err := t.tx.Commit()
+	t.db.endSession()
+	return err
+}
+
+func (t *localTx) Rollback() error {
+	err := t.tx.Rollback()
+	t.db.endSession()
 	return err
 }
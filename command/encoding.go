@@ -0,0 +1,107 @@
+package command
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Encoding names a key/value rendering mode selectable via the `set
+// encoding` command.
+type Encoding string
+
+const (
+	EncodingAuto   Encoding = "auto"
+	EncodingHex    Encoding = "hex"
+	EncodingBase64 Encoding = "base64"
+	EncodingRaw    Encoding = "raw"
+	EncodingUTF8   Encoding = "utf8"
+)
+
+// Encoder renders key/value bytes for display or export (Encode) and parses
+// that rendering back into the original bytes (Decode). A Context holds a
+// single Encoder so every command renders and parses the same way.
+type Encoder interface {
+	Encode(b []byte) string
+	Decode(s string) ([]byte, error)
+	// Name reports the Encoding this Encoder implements, so a format that
+	// embeds it (e.g. a dump header) can be loaded back with the right one.
+	Name() Encoding
+}
+
+// NewEncoder returns the Encoder for e, or an error if e is not one of the
+// modes accepted by the `set encoding` command.
+func NewEncoder(e Encoding) (Encoder, error) {
+	switch e {
+	case EncodingAuto, "":
+		return autoEncoder{}, nil
+	case EncodingHex:
+		return hexEncoder{}, nil
+	case EncodingBase64:
+		return base64Encoder{}, nil
+	case EncodingRaw:
+		return rawEncoder{}, nil
+	case EncodingUTF8:
+		return utf8Encoder{}, nil
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", e)
+	}
+}
+
+// autoEncoder is the default: printable ASCII passes through unescaped,
+// everything else (including the backslash itself) is \x-escaped.
+type autoEncoder struct{}
+
+func (autoEncoder) Encode(b []byte) string          { return safeBytesToString(b) }
+func (autoEncoder) Decode(s string) ([]byte, error) { return stringToBytes(s), nil }
+func (autoEncoder) Name() Encoding                  { return EncodingAuto }
+
+// hexEncoder renders the whole value as a single go-ethereum-style 0x-prefixed
+// hex string, handy when every byte matters (hashes, protobuf, UUIDs).
+type hexEncoder struct{}
+
+func (hexEncoder) Encode(b []byte) string { return fmt.Sprintf("%#x", b) }
+
+func (hexEncoder) Decode(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+}
+
+func (hexEncoder) Name() Encoding { return EncodingHex }
+
+type base64Encoder struct{}
+
+func (base64Encoder) Encode(b []byte) string { return base64.StdEncoding.EncodeToString(b) }
+
+func (base64Encoder) Decode(s string) ([]byte, error) { return base64.StdEncoding.DecodeString(s) }
+
+func (base64Encoder) Name() Encoding { return EncodingBase64 }
+
+// rawEncoder passes bytes through unchanged, for callers piping output
+// straight to another tool rather than reading it on a terminal.
+type rawEncoder struct{}
+
+func (rawEncoder) Encode(b []byte) string          { return string(b) }
+func (rawEncoder) Decode(s string) ([]byte, error) { return []byte(s), nil }
+func (rawEncoder) Name() Encoding                  { return EncodingRaw }
+
+// utf8Encoder is like raw but rejects values that aren't valid UTF-8, so a
+// session working with text values gets an error instead of mojibake.
+type utf8Encoder struct{}
+
+func (utf8Encoder) Encode(b []byte) string {
+	if !utf8.Valid(b) {
+		return safeBytesToString(b)
+	}
+	return string(b)
+}
+
+func (utf8Encoder) Decode(s string) ([]byte, error) {
+	if !utf8.ValidString(s) {
+		return nil, fmt.Errorf("not valid utf-8: %q", s)
+	}
+	return []byte(s), nil
+}
+
+func (utf8Encoder) Name() Encoding { return EncodingUTF8 }
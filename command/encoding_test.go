@@ -0,0 +1,68 @@
+package command
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSafeBytesToStringRoundTrip(t *testing.T) {
+	for i := 0; i <= 0xff; i++ {
+		b := []byte{byte(i)}
+		got := stringToBytes(safeBytesToString(b))
+		if !bytes.Equal(got, b) {
+			t.Fatalf("byte 0x%02x: round trip got %v", i, got)
+		}
+	}
+}
+
+func TestEncoderRoundTrip(t *testing.T) {
+	modes := []Encoding{EncodingAuto, EncodingHex, EncodingBase64, EncodingRaw}
+	for _, mode := range modes {
+		mode := mode
+		t.Run(string(mode), func(t *testing.T) {
+			enc, err := NewEncoder(mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i <= 0xff; i++ {
+				b := []byte{byte(i)}
+				got, err := enc.Decode(enc.Encode(b))
+				if err != nil {
+					t.Fatalf("byte 0x%02x: %v", i, err)
+				}
+				if !bytes.Equal(got, b) {
+					t.Fatalf("byte 0x%02x: round trip got %v", i, got)
+				}
+			}
+		})
+	}
+}
+
+func TestUTF8EncoderRoundTrip(t *testing.T) {
+	enc, err := NewEncoder(EncodingUTF8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []string{"hello", "中文English", ""}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt, func(t *testing.T) {
+			b, err := enc.Decode(enc.Encode([]byte(tt)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt {
+				t.Fatalf("got %q, want %q", b, tt)
+			}
+		})
+	}
+	if _, err := enc.Decode(string([]byte{0xff, 0xfe})); err == nil {
+		t.Fatal("expected error decoding invalid utf-8")
+	}
+}
+
+func TestNewEncoderUnknown(t *testing.T) {
+	if _, err := NewEncoder("bogus"); err == nil {
+		t.Fatal("expected error for unknown encoding")
+	}
+}
@@ -0,0 +1,520 @@
+package command
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	bolt "go.etcd.io/bbolt"
+)
+
+// importBatchSize bounds how many rows import/load apply per transaction,
+// so a large stream doesn't have to be held in memory at once.
+const importBatchSize = 1000
+
+// kvRow is the wire shape used by the json export/import format. Key/Value
+// are rendered with the session's current Encoder (see Context.Encoder), so
+// a file exported under one encoding must be imported under the same one -
+// export/import don't record which was used, unlike dump/load below.
+type kvRow struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// dumpHeader is always the first line of a dump stream, recording the
+// Encoder used so load can decode every row correctly regardless of the
+// loading session's own `set encoding`.
+type dumpHeader struct {
+	Encoding Encoding `json:"encoding"`
+}
+
+// dumpRow.Bucket is the full nested bucket path, so dump/load cover buckets
+// created with mkbucket <a> <b> <c>, not just top-level ones.
+type dumpRow struct {
+	Bucket []string `json:"bucket"`
+	Key    string   `json:"key"`
+	Value  string   `json:"value"`
+}
+
+// walkBuckets calls fn with the path of every bucket in db, depth-first,
+// including nested ones, starting from the top level.
+func walkBuckets(db DB, path []string, fn func(path []string) error) error {
+	if len(path) > 0 {
+		if err := fn(path); err != nil {
+			return err
+		}
+	}
+	names, err := db.SubBuckets(path)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		child := append(append([]string{}, path...), name)
+		if err := walkBuckets(db, child, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseOptions pulls "--flag value" pairs named in opts out of args,
+// writing each value to its target and returning the remaining positional
+// arguments in order.
+func parseOptions(args []string, opts map[string]*string) []string {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		if p, ok := opts[args[i]]; ok && i+1 < len(args) {
+			*p = args[i+1]
+			i++
+			continue
+		}
+		rest = append(rest, args[i])
+	}
+	return rest
+}
+
+// parseFlag pulls a valueless "--flag" out of args, reporting whether it
+// was present and returning the remaining arguments.
+func parseFlag(args []string, flag string) ([]string, bool) {
+	var rest []string
+	found := false
+	for _, a := range args {
+		if a == flag {
+			found = true
+			continue
+		}
+		rest = append(rest, a)
+	}
+	return rest, found
+}
+
+func isTTY(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && isatty.IsTerminal(f.Fd())
+}
+
+func encodeRow(w io.Writer, format string, key, value string, jsonEnc *json.Encoder, csvW *csv.Writer) error {
+	switch format {
+	case "csv":
+		return csvW.Write([]string{key, value})
+	case "kv":
+		_, err := fmt.Fprintf(w, "%s\t%s\n", key, value)
+		return err
+	default:
+		return jsonEnc.Encode(kvRow{Key: key, Value: value})
+	}
+}
+
+// RegisterIOCommands adds the export, import, dump and load verbs to r.
+func RegisterIOCommands(r Register, db DB) {
+	r.Register(&exportCommand{db: db})
+	r.Register(&importCommand{db: db})
+	r.Register(&dumpCommand{db: db})
+	r.Register(&loadCommand{db: db})
+}
+
+type exportCommand struct {
+	db DB
+}
+
+func (c *exportCommand) Alias() []string { return []string{"export"} }
+
+func (c *exportCommand) Help() string {
+	return "Export a bucket: export <bucket> [--format json|csv|kv] [--out file]"
+}
+
+func (c *exportCommand) ReadOnly() bool { return true }
+
+func (c *exportCommand) Check(ctx *Context, args []string) error {
+	return NewValidats().MinArgs(1).Finish()(ctx, args)
+}
+
+func (c *exportCommand) Execute(ctx *Context, args []string) error {
+	format := "json"
+	outFile := ""
+	rest := parseOptions(args, map[string]*string{"--format": &format, "--out": &outFile})
+	if len(rest) != 1 {
+		return fmt.Errorf("expect exactly 1 bucket argument")
+	}
+	bucket := bucketPath(rest)
+
+	w := ctx.Output()
+	paged := outFile == "" && isTTY(w)
+	if outFile != "" {
+		f, err := os.Create(outFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	jsonEnc := json.NewEncoder(w)
+	csvW := csv.NewWriter(w)
+	defer csvW.Flush()
+
+	enc := ctx.Encoder()
+	var n int
+	err := c.db.ListKeys(bucket, true, func(k, v []byte) error {
+		if err := encodeRow(w, format, enc.Encode(k), enc.Encode(v), jsonEnc, csvW); err != nil {
+			return err
+		}
+		n++
+		if paged && n%askContinueSize == 0 {
+			csvW.Flush()
+			if !askContinue(ctx) {
+				return errExit
+			}
+		}
+		return nil
+	})
+	if err == errExit { // nolint:errorlint
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+	ctx.Printf("exported %d rows\n", n)
+	return nil
+}
+
+type importCommand struct {
+	db DB
+}
+
+func (c *importCommand) Alias() []string { return []string{"import"} }
+
+func (c *importCommand) Help() string {
+	return "Import a bucket: import <bucket> [--format json|csv|kv] [--in file] [--create]"
+}
+
+func (c *importCommand) ReadOnly() bool { return false }
+
+func (c *importCommand) Check(ctx *Context, args []string) error {
+	return NewValidats().MinArgs(1).Finish()(ctx, args)
+}
+
+func (c *importCommand) Execute(ctx *Context, args []string) error {
+	format := "json"
+	inFile := ""
+	args, create := parseFlag(args, "--create")
+	rest := parseOptions(args, map[string]*string{"--format": &format, "--in": &inFile})
+	if len(rest) != 1 {
+		return fmt.Errorf("expect exactly 1 bucket argument")
+	}
+	bucket := bucketPath(rest)
+
+	r := io.Reader(os.Stdin)
+	if inFile != "" {
+		f, err := os.Open(inFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	// If a begin/commit/rollback session is open, apply writes to it like
+	// every other write command does (see dbCommand.Execute); otherwise
+	// batch rows through db.BatchUpdate, which is atomic per chunk and
+	// (unlike db.Begin) also works against a --remote database.
+	db := c.db
+	tx := ctx.Tx()
+	if tx != nil {
+		db = tx
+	}
+
+	if create {
+		if err := db.CreateBucket(bucket); err != nil {
+			return err
+		}
+	}
+
+	var n int
+	var chunk []Mutation
+	flushChunk := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		if tx != nil {
+			for _, m := range chunk {
+				if err := tx.Put(m.Bucket, m.Key, m.Value); err != nil {
+					return err
+				}
+			}
+		} else if err := c.db.BatchUpdate(chunk); err != nil {
+			return err
+		}
+		chunk = chunk[:0]
+		return nil
+	}
+	err := decodeRows(r, format, ctx.Encoder().Decode, func(key, value []byte) error {
+		chunk = append(chunk, Mutation{Bucket: bucket, Key: key, Value: value})
+		n++
+		if len(chunk) >= importBatchSize {
+			return flushChunk()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := flushChunk(); err != nil {
+		return err
+	}
+	ctx.Printf("imported %d rows\n", n)
+	return nil
+}
+
+func decodeRows(r io.Reader, format string, decode func(string) ([]byte, error), fn func(key, value []byte) error) error {
+	decodePair := func(k, v string) error {
+		key, err := decode(k)
+		if err != nil {
+			return err
+		}
+		value, err := decode(v)
+		if err != nil {
+			return err
+		}
+		return fn(key, value)
+	}
+	switch format {
+	case "csv":
+		cr := csv.NewReader(r)
+		for {
+			rec, err := cr.Read()
+			if err == io.EOF { // nolint:errorlint
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if len(rec) != 2 {
+				return fmt.Errorf("expect 2 csv fields, got %d", len(rec))
+			}
+			if err := decodePair(rec[0], rec[1]); err != nil {
+				return err
+			}
+		}
+	case "kv":
+		sc := bufio.NewScanner(r)
+		for sc.Scan() {
+			parts := strings.SplitN(sc.Text(), "\t", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("expect a key<TAB>value line, got %q", sc.Text())
+			}
+			if err := decodePair(parts[0], parts[1]); err != nil {
+				return err
+			}
+		}
+		return sc.Err()
+	default:
+		dec := json.NewDecoder(r)
+		for dec.More() {
+			var row kvRow
+			if err := dec.Decode(&row); err != nil {
+				return err
+			}
+			if err := decodePair(row.Key, row.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+type dumpCommand struct {
+	db DB
+}
+
+func (c *dumpCommand) Alias() []string { return []string{"dump"} }
+
+func (c *dumpCommand) Help() string {
+	return "Dump the whole database as newline-delimited JSON: dump [--out file]"
+}
+
+func (c *dumpCommand) ReadOnly() bool { return true }
+
+func (c *dumpCommand) Check(ctx *Context, args []string) error {
+	return NewValidats().MaxArgs(2).Finish()(ctx, args)
+}
+
+func (c *dumpCommand) Execute(ctx *Context, args []string) error {
+	outFile := ""
+	rest := parseOptions(args, map[string]*string{"--out": &outFile})
+	if len(rest) != 0 {
+		return fmt.Errorf("dump takes no positional arguments")
+	}
+
+	w := ctx.Output()
+	if outFile != "" {
+		f, err := os.Create(outFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	jsonEnc := json.NewEncoder(w)
+	enc := ctx.Encoder()
+	if err := jsonEnc.Encode(dumpHeader{Encoding: enc.Name()}); err != nil {
+		return err
+	}
+	var n, nb int
+	err := walkBuckets(c.db, nil, func(path []string) error {
+		nb++
+		return c.db.ListKeys(path, true, func(k, v []byte) error {
+			if v == nil {
+				// A nested bucket, not a key/value pair; walkBuckets
+				// already visits it on its own.
+				return nil
+			}
+			n++
+			bucket := append([]string(nil), path...)
+			return jsonEnc.Encode(dumpRow{Bucket: bucket, Key: enc.Encode(k), Value: enc.Encode(v)})
+		})
+	})
+	if err != nil {
+		return err
+	}
+	ctx.Printf("dumped %d rows from %d buckets\n", n, nb)
+	return nil
+}
+
+type loadCommand struct {
+	db DB
+}
+
+func (c *loadCommand) Alias() []string { return []string{"load"} }
+
+func (c *loadCommand) Help() string {
+	return "Load a dump produced by the dump command: load <file>"
+}
+
+func (c *loadCommand) ReadOnly() bool { return false }
+
+func (c *loadCommand) Check(ctx *Context, args []string) error {
+	return NewValidats().NumArgs(1).Finish()(ctx, args)
+}
+
+func (c *loadCommand) Execute(ctx *Context, args []string) error {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// See importCommand.Execute: apply to an open session's tx if present,
+	// otherwise batch rows through db.BatchUpdate so load also works
+	// against a --remote database, which has no db.Begin.
+	db := c.db
+	tx := ctx.Tx()
+	if tx != nil {
+		db = tx
+	}
+
+	var n int
+	var muts []Mutation
+	flush := func() error {
+		if len(muts) == 0 {
+			return nil
+		}
+		if tx != nil {
+			for _, m := range muts {
+				if err := tx.Put(m.Bucket, m.Key, m.Value); err != nil {
+					return err
+				}
+			}
+		} else if err := db.BatchUpdate(muts); err != nil {
+			return err
+		}
+		muts = muts[:0]
+		return nil
+	}
+
+	dec := json.NewDecoder(f)
+	var hdr dumpHeader
+	if !dec.More() {
+		ctx.Printf("loaded 0 rows\n")
+		return nil
+	}
+	if err := dec.Decode(&hdr); err != nil {
+		return err
+	}
+	enc, err := NewEncoder(hdr.Encoding)
+	if err != nil {
+		return fmt.Errorf("invalid dump header: %w", err)
+	}
+	for dec.More() {
+		var row dumpRow
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		key, err := enc.Decode(row.Key)
+		if err != nil {
+			return err
+		}
+		value, err := enc.Decode(row.Value)
+		if err != nil {
+			return err
+		}
+		muts = append(muts, Mutation{Bucket: row.Bucket, Key: key, Value: value})
+		n++
+		if len(muts) >= importBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	ctx.Printf("loaded %d rows\n", n)
+	return nil
+}
+
+// RegisterSnapshotCommand adds the snapshot verb, which atomically clones
+// the database file via bbolt's own tx.WriteTo. This only makes sense for a
+// local database, since it streams the raw bbolt file format rather than
+// going through the DB interface.
+func RegisterSnapshotCommand(r Register, db *bolt.DB) {
+	r.Register(&snapshotCommand{db: db})
+}
+
+type snapshotCommand struct {
+	db *bolt.DB
+}
+
+func (c *snapshotCommand) Alias() []string { return []string{"snapshot"} }
+
+func (c *snapshotCommand) Help() string {
+	return "Atomically clone the database file: snapshot <out.db>"
+}
+
+func (c *snapshotCommand) ReadOnly() bool { return true }
+
+func (c *snapshotCommand) Check(ctx *Context, args []string) error {
+	return NewValidats().NumArgs(1).Finish()(ctx, args)
+}
+
+func (c *snapshotCommand) Execute(ctx *Context, args []string) error {
+	f, err := os.Create(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := c.db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(f)
+		return err
+	}); err != nil {
+		return err
+	}
+	ctx.Printf("OK\n")
+	return nil
+}
@@ -0,0 +1,150 @@
+package command
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		opts []string
+		rest []string
+		vals []string
+	}{
+		{"no options", []string{"bucket"}, nil, []string{"bucket"}, nil},
+		{
+			"one option", []string{"bucket", "--format", "csv"},
+			[]string{"--format"}, []string{"bucket"}, []string{"csv"},
+		},
+		{
+			"option with no value left", []string{"bucket", "--format"},
+			[]string{"--format"}, []string{"bucket", "--format"}, []string{""},
+		},
+		{
+			"unknown flag passed through", []string{"bucket", "--nope", "x"},
+			[]string{"--format"}, []string{"bucket", "--nope", "x"}, []string{""},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			opts := map[string]*string{}
+			var vals []*string
+			for _, o := range tt.opts {
+				var v string
+				opts[o] = &v
+				vals = append(vals, &v)
+			}
+			rest := parseOptions(tt.args, opts)
+			if !reflect.DeepEqual(rest, tt.rest) {
+				t.Fatalf("rest = %q, want %q", rest, tt.rest)
+			}
+			for i, v := range vals {
+				if *v != tt.vals[i] {
+					t.Fatalf("opt %d = %q, want %q", i, *v, tt.vals[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseFlag(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		flag  string
+		rest  []string
+		found bool
+	}{
+		{"present", []string{"bucket", "--create"}, "--create", []string{"bucket"}, true},
+		{"absent", []string{"bucket"}, "--create", []string{"bucket"}, false},
+		{"only flag", []string{"--create"}, "--create", nil, true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			rest, found := parseFlag(tt.args, tt.flag)
+			if found != tt.found {
+				t.Fatalf("found = %v, want %v", found, tt.found)
+			}
+			if !reflect.DeepEqual(rest, tt.rest) {
+				t.Fatalf("rest = %q, want %q", rest, tt.rest)
+			}
+		})
+	}
+}
+
+func TestDecodeRowsFormats(t *testing.T) {
+	identity := func(s string) ([]byte, error) { return []byte(s), nil }
+	tests := []struct {
+		format string
+		in     string
+		want   [][2]string
+	}{
+		{"json", `{"key":"k1","value":"v1"}` + "\n" + `{"key":"k2","value":"v2"}` + "\n", [][2]string{{"k1", "v1"}, {"k2", "v2"}}},
+		{"csv", "k1,v1\nk2,v2\n", [][2]string{{"k1", "v1"}, {"k2", "v2"}}},
+		{"kv", "k1\tv1\nk2\tv2\n", [][2]string{{"k1", "v1"}, {"k2", "v2"}}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.format, func(t *testing.T) {
+			var got [][2]string
+			err := decodeRows(strings.NewReader(tt.in), tt.format, identity, func(key, value []byte) error {
+				got = append(got, [2]string{string(key), string(value)})
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeRowsKVBadLine(t *testing.T) {
+	identity := func(s string) ([]byte, error) { return []byte(s), nil }
+	err := decodeRows(strings.NewReader("no-tab-here\n"), "kv", identity, func(key, value []byte) error {
+		return nil
+	})
+	if err == nil {
+		t.Fatal("expected error for line without a tab")
+	}
+}
+
+func TestEncodeRowRoundTrip(t *testing.T) {
+	tests := []string{"json", "csv", "kv"}
+	for _, format := range tests {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			jsonEnc := json.NewEncoder(&buf)
+			csvW := csv.NewWriter(&buf)
+			if err := encodeRow(&buf, format, "k1", "v1", jsonEnc, csvW); err != nil {
+				t.Fatal(err)
+			}
+			csvW.Flush()
+
+			identity := func(s string) ([]byte, error) { return []byte(s), nil }
+			var got [][2]string
+			err := decodeRows(strings.NewReader(buf.String()), format, identity, func(key, value []byte) error {
+				got = append(got, [2]string{string(key), string(value)})
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := [][2]string{{"k1", "v1"}}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
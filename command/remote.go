@@ -0,0 +1,691 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// DB is the set of operations a command executor needs from a database.
+// localDB satisfies it against an on-disk *bbolt.DB; remoteDB satisfies it
+// against a boltdb-cli gRPC server, so the CLI, history, and table printing
+// work identically whether the file is opened locally or over --remote.
+type DB interface {
+	Stat() (DBStat, error)
+	ListBuckets() ([]BucketStat, error)
+	ListKeys(bucket []string, withValue bool, fn func(k, v []byte) error) error
+	Get(bucket []string, key []byte) ([]byte, bool, error)
+	Put(bucket []string, key, value []byte) error
+	Delete(bucket []string, key []byte) error
+	CreateBucket(bucket []string) error
+	DeleteBucket(bucket []string) error
+	// SubBuckets lists the names of buckets nested directly under bucket
+	// (or the top-level buckets, if bucket is empty). Callers that need to
+	// walk the whole bucket tree (dump, load) recurse with it.
+	SubBuckets(bucket []string) ([]string, error)
+	// BatchUpdate applies muts atomically in a single transaction. Callers
+	// that need to write many rows without holding a begin/commit/rollback
+	// session (import, load) use this instead of Begin so the writes stay
+	// atomic against both a local file and a --remote server.
+	BatchUpdate(muts []Mutation) error
+	// Begin opens a long-lived transaction for an interactive
+	// begin/commit/rollback session.
+	Begin() (Tx, error)
+}
+
+// Tx is a DB bound to a single transaction: every operation shares it
+// instead of opening a new one, until Commit or Rollback is called.
+type Tx interface {
+	DB
+	Commit() error
+	Rollback() error
+}
+
+// DBStat mirrors the summary row printed by the stat command.
+type DBStat struct {
+	TotalKeys int64
+	Buckets   int64
+	MaxDepth  int64
+}
+
+// BucketStat describes one top-level bucket, as listed by the buckets command.
+type BucketStat struct {
+	Name  string
+	Keys  int64
+	Depth int64
+}
+
+// jsonCodec marshals gRPC messages as JSON instead of protobuf wire format,
+// so the RemoteDB service can be implemented with plain Go structs and no
+// protoc-generated code.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return "json" }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// RESP protocol messages for the RemoteDB gRPC service.
+
+type StatRequest struct{}
+
+type ListBucketsRequest struct{}
+
+type ListBucketsReply struct {
+	Buckets []BucketStat
+}
+
+type ListKeysRequest struct {
+	Bucket    []string
+	WithValue bool
+}
+
+type KV struct {
+	Key   []byte
+	Value []byte
+}
+
+type GetRequest struct {
+	Bucket []string
+	Key    []byte
+}
+
+type GetReply struct {
+	Value []byte
+	Found bool
+}
+
+type PutRequest struct {
+	Bucket []string
+	Key    []byte
+	Value  []byte
+}
+
+type DeleteRequest struct {
+	Bucket []string
+	Key    []byte
+}
+
+type CreateBucketRequest struct {
+	Bucket []string
+}
+
+type DeleteBucketRequest struct {
+	Bucket []string
+}
+
+type SubBucketsRequest struct {
+	Bucket []string
+}
+
+type SubBucketsReply struct {
+	Names []string
+}
+
+type Mutation struct {
+	Delete bool
+	Bucket []string
+	Key    []byte
+	Value  []byte
+}
+
+type BatchReply struct {
+	Applied int64
+}
+
+type Empty struct{}
+
+const remoteDBServiceName = "boltdbcli.RemoteDB"
+
+var remoteDBServiceDesc = grpc.ServiceDesc{
+	ServiceName: remoteDBServiceName,
+	HandlerType: (*remoteDBServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Stat", Handler: remoteDBStatHandler},
+		{MethodName: "ListBuckets", Handler: remoteDBListBucketsHandler},
+		{MethodName: "Get", Handler: remoteDBGetHandler},
+		{MethodName: "Put", Handler: remoteDBPutHandler},
+		{MethodName: "Delete", Handler: remoteDBDeleteHandler},
+		{MethodName: "CreateBucket", Handler: remoteDBCreateBucketHandler},
+		{MethodName: "DeleteBucket", Handler: remoteDBDeleteBucketHandler},
+		{MethodName: "SubBuckets", Handler: remoteDBSubBucketsHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "ListKeys", Handler: remoteDBListKeysHandler, ServerStreams: true},
+		{StreamName: "Batch", Handler: remoteDBBatchHandler, ClientStreams: true},
+	},
+	Metadata: "boltdb-cli/remote.go",
+}
+
+// remoteDBServer is implemented by RemoteDBServer; it is the HandlerType
+// for remoteDBServiceDesc.
+type remoteDBServer interface {
+	Stat(context.Context, *StatRequest) (*DBStat, error)
+	ListBuckets(context.Context, *ListBucketsRequest) (*ListBucketsReply, error)
+	Get(context.Context, *GetRequest) (*GetReply, error)
+	Put(context.Context, *PutRequest) (*Empty, error)
+	Delete(context.Context, *DeleteRequest) (*Empty, error)
+	CreateBucket(context.Context, *CreateBucketRequest) (*Empty, error)
+	DeleteBucket(context.Context, *DeleteBucketRequest) (*Empty, error)
+	SubBuckets(context.Context, *SubBucketsRequest) (*SubBucketsReply, error)
+	ListKeys(*ListKeysRequest, RemoteDBListKeysServer) error
+	Batch(RemoteDBBatchServer) error
+}
+
+func remoteDBStatHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(remoteDBServer).Stat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + remoteDBServiceName + "/Stat"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(remoteDBServer).Stat(ctx, req.(*StatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func remoteDBListBucketsHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBucketsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(remoteDBServer).ListBuckets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + remoteDBServiceName + "/ListBuckets"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(remoteDBServer).ListBuckets(ctx, req.(*ListBucketsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func remoteDBGetHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(remoteDBServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + remoteDBServiceName + "/Get"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(remoteDBServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func remoteDBPutHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(remoteDBServer).Put(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + remoteDBServiceName + "/Put"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(remoteDBServer).Put(ctx, req.(*PutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func remoteDBDeleteHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(remoteDBServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + remoteDBServiceName + "/Delete"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(remoteDBServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func remoteDBCreateBucketHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBucketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(remoteDBServer).CreateBucket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + remoteDBServiceName + "/CreateBucket"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(remoteDBServer).CreateBucket(ctx, req.(*CreateBucketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func remoteDBDeleteBucketHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBucketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(remoteDBServer).DeleteBucket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + remoteDBServiceName + "/DeleteBucket"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(remoteDBServer).DeleteBucket(ctx, req.(*DeleteBucketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func remoteDBSubBucketsHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubBucketsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(remoteDBServer).SubBuckets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + remoteDBServiceName + "/SubBuckets"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(remoteDBServer).SubBuckets(ctx, req.(*SubBucketsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RemoteDBListKeysServer is the server-side stream for the ListKeys RPC.
+type RemoteDBListKeysServer interface {
+	Send(*KV) error
+	grpc.ServerStream
+}
+
+type remoteDBListKeysServer struct{ grpc.ServerStream }
+
+func (x *remoteDBListKeysServer) Send(m *KV) error { return x.ServerStream.SendMsg(m) }
+
+func remoteDBListKeysHandler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListKeysRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(remoteDBServer).ListKeys(m, &remoteDBListKeysServer{stream})
+}
+
+// RemoteDBBatchServer is the server-side stream for the Batch RPC.
+type RemoteDBBatchServer interface {
+	SendAndClose(*BatchReply) error
+	Recv() (*Mutation, error)
+	grpc.ServerStream
+}
+
+type remoteDBBatchServer struct{ grpc.ServerStream }
+
+func (x *remoteDBBatchServer) SendAndClose(m *BatchReply) error { return x.ServerStream.SendMsg(m) }
+
+func (x *remoteDBBatchServer) Recv() (*Mutation, error) {
+	m := new(Mutation)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func remoteDBBatchHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(remoteDBServer).Batch(&remoteDBBatchServer{stream})
+}
+
+// RemoteDBClient is the client side of the RemoteDB gRPC service.
+type RemoteDBClient interface {
+	Stat(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*DBStat, error)
+	ListBuckets(ctx context.Context, in *ListBucketsRequest, opts ...grpc.CallOption) (*ListBucketsReply, error)
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetReply, error)
+	Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*Empty, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*Empty, error)
+	CreateBucket(ctx context.Context, in *CreateBucketRequest, opts ...grpc.CallOption) (*Empty, error)
+	DeleteBucket(ctx context.Context, in *DeleteBucketRequest, opts ...grpc.CallOption) (*Empty, error)
+	SubBuckets(ctx context.Context, in *SubBucketsRequest, opts ...grpc.CallOption) (*SubBucketsReply, error)
+	ListKeys(ctx context.Context, in *ListKeysRequest, opts ...grpc.CallOption) (RemoteDBListKeysClient, error)
+	Batch(ctx context.Context, opts ...grpc.CallOption) (RemoteDBBatchClient, error)
+}
+
+type remoteDBClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewRemoteDBClient wraps a gRPC client connection as a RemoteDBClient.
+func NewRemoteDBClient(cc grpc.ClientConnInterface) RemoteDBClient {
+	return &remoteDBClient{cc}
+}
+
+func (c *remoteDBClient) Stat(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*DBStat, error) {
+	out := new(DBStat)
+	if err := c.cc.Invoke(ctx, "/"+remoteDBServiceName+"/Stat", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remoteDBClient) ListBuckets(ctx context.Context, in *ListBucketsRequest, opts ...grpc.CallOption) (*ListBucketsReply, error) {
+	out := new(ListBucketsReply)
+	if err := c.cc.Invoke(ctx, "/"+remoteDBServiceName+"/ListBuckets", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remoteDBClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetReply, error) {
+	out := new(GetReply)
+	if err := c.cc.Invoke(ctx, "/"+remoteDBServiceName+"/Get", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remoteDBClient) Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	if err := c.cc.Invoke(ctx, "/"+remoteDBServiceName+"/Put", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remoteDBClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	if err := c.cc.Invoke(ctx, "/"+remoteDBServiceName+"/Delete", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remoteDBClient) CreateBucket(ctx context.Context, in *CreateBucketRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	if err := c.cc.Invoke(ctx, "/"+remoteDBServiceName+"/CreateBucket", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remoteDBClient) DeleteBucket(ctx context.Context, in *DeleteBucketRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	if err := c.cc.Invoke(ctx, "/"+remoteDBServiceName+"/DeleteBucket", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remoteDBClient) SubBuckets(ctx context.Context, in *SubBucketsRequest, opts ...grpc.CallOption) (*SubBucketsReply, error) {
+	out := new(SubBucketsReply)
+	if err := c.cc.Invoke(ctx, "/"+remoteDBServiceName+"/SubBuckets", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RemoteDBListKeysClient is the client side of the ListKeys stream.
+type RemoteDBListKeysClient interface {
+	Recv() (*KV, error)
+	grpc.ClientStream
+}
+
+type remoteDBListKeysClient struct{ grpc.ClientStream }
+
+func (x *remoteDBListKeysClient) Recv() (*KV, error) {
+	m := new(KV)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *remoteDBClient) ListKeys(ctx context.Context, in *ListKeysRequest, opts ...grpc.CallOption) (RemoteDBListKeysClient, error) {
+	stream, err := c.cc.NewStream(ctx, &remoteDBServiceDesc.Streams[0], "/"+remoteDBServiceName+"/ListKeys", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &remoteDBListKeysClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// RemoteDBBatchClient is the client side of the Batch stream.
+type RemoteDBBatchClient interface {
+	Send(*Mutation) error
+	CloseAndRecv() (*BatchReply, error)
+	grpc.ClientStream
+}
+
+type remoteDBBatchClient struct{ grpc.ClientStream }
+
+func (x *remoteDBBatchClient) Send(m *Mutation) error { return x.ClientStream.SendMsg(m) }
+
+func (x *remoteDBBatchClient) CloseAndRecv() (*BatchReply, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(BatchReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *remoteDBClient) Batch(ctx context.Context, opts ...grpc.CallOption) (RemoteDBBatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &remoteDBServiceDesc.Streams[1], "/"+remoteDBServiceName+"/Batch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteDBBatchClient{stream}, nil
+}
+
+// RemoteDBServer implements the RemoteDB gRPC service on top of a local DB,
+// so `boltdb-cli serve --grpc :port <file>` can expose it to clients.
+type RemoteDBServer struct {
+	db DB
+}
+
+// NewRemoteDBServer wraps db as a gRPC service.
+func NewRemoteDBServer(db DB) *RemoteDBServer {
+	return &RemoteDBServer{db: db}
+}
+
+// Register adds the RemoteDB service to s.
+func (r *RemoteDBServer) Register(s *grpc.Server) {
+	s.RegisterService(&remoteDBServiceDesc, r)
+}
+
+func (r *RemoteDBServer) Stat(context.Context, *StatRequest) (*DBStat, error) {
+	st, err := r.db.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+func (r *RemoteDBServer) ListBuckets(context.Context, *ListBucketsRequest) (*ListBucketsReply, error) {
+	buckets, err := r.db.ListBuckets()
+	if err != nil {
+		return nil, err
+	}
+	return &ListBucketsReply{Buckets: buckets}, nil
+}
+
+func (r *RemoteDBServer) Get(_ context.Context, in *GetRequest) (*GetReply, error) {
+	v, found, err := r.db.Get(in.Bucket, in.Key)
+	if err != nil {
+		return nil, err
+	}
+	return &GetReply{Value: v, Found: found}, nil
+}
+
+func (r *RemoteDBServer) Put(_ context.Context, in *PutRequest) (*Empty, error) {
+	return &Empty{}, r.db.Put(in.Bucket, in.Key, in.Value)
+}
+
+func (r *RemoteDBServer) Delete(_ context.Context, in *DeleteRequest) (*Empty, error) {
+	return &Empty{}, r.db.Delete(in.Bucket, in.Key)
+}
+
+func (r *RemoteDBServer) CreateBucket(_ context.Context, in *CreateBucketRequest) (*Empty, error) {
+	return &Empty{}, r.db.CreateBucket(in.Bucket)
+}
+
+func (r *RemoteDBServer) DeleteBucket(_ context.Context, in *DeleteBucketRequest) (*Empty, error) {
+	return &Empty{}, r.db.DeleteBucket(in.Bucket)
+}
+
+func (r *RemoteDBServer) ListKeys(in *ListKeysRequest, stream RemoteDBListKeysServer) error {
+	return r.db.ListKeys(in.Bucket, in.WithValue, func(k, v []byte) error {
+		kv := &KV{Key: k}
+		if in.WithValue {
+			kv.Value = v
+		}
+		return stream.Send(kv)
+	})
+}
+
+func (r *RemoteDBServer) SubBuckets(_ context.Context, in *SubBucketsRequest) (*SubBucketsReply, error) {
+	names, err := r.db.SubBuckets(in.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	return &SubBucketsReply{Names: names}, nil
+}
+
+func (r *RemoteDBServer) Batch(stream RemoteDBBatchServer) error {
+	var muts []Mutation
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF { // nolint:errorlint
+			break
+		}
+		if err != nil {
+			return err
+		}
+		muts = append(muts, *m)
+	}
+	if err := r.db.BatchUpdate(muts); err != nil {
+		return err
+	}
+	return stream.SendAndClose(&BatchReply{Applied: int64(len(muts))})
+}
+
+// remoteDB is a DB backed by a RemoteDBClient, used when boltdb-cli is
+// started with --remote grpc://host:port instead of a local file.
+type remoteDB struct {
+	client RemoteDBClient
+}
+
+// NewRemoteDB dials addr and returns a DB that operates against the
+// boltdb-cli gRPC server listening there.
+func NewRemoteDB(addr string) (DB, error) {
+	conn, err := grpc.Dial(addr, grpc.WithInsecure(), grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{}))) // nolint:staticcheck
+	if err != nil {
+		return nil, err
+	}
+	return &remoteDB{client: NewRemoteDBClient(conn)}, nil
+}
+
+func (r *remoteDB) Stat() (DBStat, error) {
+	reply, err := r.client.Stat(context.Background(), &StatRequest{})
+	if err != nil {
+		return DBStat{}, err
+	}
+	return *reply, nil
+}
+
+func (r *remoteDB) ListBuckets() ([]BucketStat, error) {
+	reply, err := r.client.ListBuckets(context.Background(), &ListBucketsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return reply.Buckets, nil
+}
+
+func (r *remoteDB) ListKeys(bucket []string, withValue bool, fn func(k, v []byte) error) error {
+	stream, err := r.client.ListKeys(context.Background(), &ListKeysRequest{Bucket: bucket, WithValue: withValue})
+	if err != nil {
+		return err
+	}
+	for {
+		kv, err := stream.Recv()
+		if err == io.EOF { // nolint:errorlint
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(kv.Key, kv.Value); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *remoteDB) Get(bucket []string, key []byte) ([]byte, bool, error) {
+	reply, err := r.client.Get(context.Background(), &GetRequest{Bucket: bucket, Key: key})
+	if err != nil {
+		return nil, false, err
+	}
+	return reply.Value, reply.Found, nil
+}
+
+func (r *remoteDB) Put(bucket []string, key, value []byte) error {
+	_, err := r.client.Put(context.Background(), &PutRequest{Bucket: bucket, Key: key, Value: value})
+	return err
+}
+
+func (r *remoteDB) Delete(bucket []string, key []byte) error {
+	_, err := r.client.Delete(context.Background(), &DeleteRequest{Bucket: bucket, Key: key})
+	return err
+}
+
+func (r *remoteDB) CreateBucket(bucket []string) error {
+	_, err := r.client.CreateBucket(context.Background(), &CreateBucketRequest{Bucket: bucket})
+	return err
+}
+
+func (r *remoteDB) DeleteBucket(bucket []string) error {
+	_, err := r.client.DeleteBucket(context.Background(), &DeleteBucketRequest{Bucket: bucket})
+	return err
+}
+
+func (r *remoteDB) SubBuckets(bucket []string) ([]string, error) {
+	reply, err := r.client.SubBuckets(context.Background(), &SubBucketsRequest{Bucket: bucket})
+	if err != nil {
+		return nil, err
+	}
+	return reply.Names, nil
+}
+
+// BatchUpdate streams muts to the server over the Batch RPC, which applies
+// them in one local transaction (see RemoteDBServer.Batch).
+func (r *remoteDB) BatchUpdate(muts []Mutation) error {
+	stream, err := r.client.Batch(context.Background())
+	if err != nil {
+		return err
+	}
+	for i := range muts {
+		if err := stream.Send(&muts[i]); err != nil {
+			return err
+		}
+	}
+	_, err = stream.CloseAndRecv()
+	return err
+}
+
+// Begin is not supported over --remote: the RemoteDB service has no
+// long-lived-transaction RPC, so begin/commit/rollback sessions only work
+// against a local database.
+func (r *remoteDB) Begin() (Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported against a --remote database")
+}
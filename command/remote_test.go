@@ -0,0 +1,22 @@
+package command
+
+import "testing"
+
+func TestJSONCodecRoundTrip(t *testing.T) {
+	var c jsonCodec
+	if c.Name() != "json" {
+		t.Fatalf("got %q, want %q", c.Name(), "json")
+	}
+	in := KV{Key: []byte("k1"), Value: []byte("v1")}
+	b, err := c.Marshal(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out KV
+	if err := c.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if string(out.Key) != "k1" || string(out.Value) != "v1" {
+		t.Fatalf("got %+v, want %+v", out, in)
+	}
+}
@@ -0,0 +1,513 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// RESPReply is a value that can be written back to a RESP client.
+type RESPReply interface {
+	WriteTo(w *bufio.Writer) error
+}
+
+type respSimpleString string
+
+func (s respSimpleString) WriteTo(w *bufio.Writer) error {
+	_, err := fmt.Fprintf(w, "+%s\r\n", string(s))
+	return err
+}
+
+type respError string
+
+func (e respError) WriteTo(w *bufio.Writer) error {
+	_, err := fmt.Fprintf(w, "-%s\r\n", string(e))
+	return err
+}
+
+type respInteger int64
+
+func (i respInteger) WriteTo(w *bufio.Writer) error {
+	_, err := fmt.Fprintf(w, ":%d\r\n", int64(i))
+	return err
+}
+
+type respBulkString struct {
+	valid bool
+	value []byte
+}
+
+func respNilBulk() respBulkString { return respBulkString{} }
+
+func respBulk(b []byte) respBulkString { return respBulkString{valid: true, value: b} }
+
+func (b respBulkString) WriteTo(w *bufio.Writer) error {
+	if !b.valid {
+		_, err := w.WriteString("$-1\r\n")
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "$%d\r\n", len(b.value)); err != nil {
+		return err
+	}
+	if _, err := w.Write(b.value); err != nil {
+		return err
+	}
+	_, err := w.WriteString("\r\n")
+	return err
+}
+
+type respArray struct {
+	valid bool
+	items []RESPReply
+}
+
+func respArrayOf(items ...RESPReply) respArray { return respArray{valid: true, items: items} }
+
+func (a respArray) WriteTo(w *bufio.Writer) error {
+	if !a.valid {
+		_, err := w.WriteString("*-1\r\n")
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "*%d\r\n", len(a.items)); err != nil {
+		return err
+	}
+	for _, it := range a.items {
+		if err := it.WriteTo(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func respErrorf(format string, args ...interface{}) respError {
+	return respError("ERR " + fmt.Sprintf(format, args...))
+}
+
+// RESPServer serves the contents of a *bbolt.DB over the Redis wire
+// protocol (RESP), so existing redis clients and benchmarks can talk to a
+// bolt file without embedding boltdb in their own process.
+//
+// Plain key/value commands (GET/SET/DEL/EXISTS/KEYS/SCAN) operate on a
+// single top-level bucket per connection, selected with SELECT from the
+// list of buckets the server was started with. Hash commands address an
+// arbitrary bucket directly: the hash key is the bucket name and the hash
+// field is the key within it.
+type RESPServer struct {
+	db       *bolt.DB
+	buckets  []string
+	handlers map[string]func(*respSession, [][]byte) RESPReply
+}
+
+// NewRESPServer returns a RESPServer backed by db. buckets is the ordered
+// list of bucket names selectable via SELECT; buckets[0] is used by new
+// connections until they issue a SELECT. If buckets is empty, "default" is
+// used.
+func NewRESPServer(db *bolt.DB, buckets []string) *RESPServer {
+	if len(buckets) == 0 {
+		buckets = []string{"default"}
+	}
+	s := &RESPServer{db: db, buckets: buckets}
+	s.handlers = map[string]func(*respSession, [][]byte) RESPReply{
+		"PING":    s.cmdPing,
+		"SELECT":  s.cmdSelect,
+		"COMMAND": s.cmdCommand,
+		"QUIT":    s.cmdQuit,
+		"GET":     s.cmdGet,
+		"SET":     s.cmdSet,
+		"DEL":     s.cmdDel,
+		"EXISTS":  s.cmdExists,
+		"KEYS":    s.cmdKeys,
+		"SCAN":    s.cmdScan,
+		"HGET":    s.cmdHGet,
+		"HSET":    s.cmdHSet,
+		"HDEL":    s.cmdHDel,
+		"HKEYS":   s.cmdHKeys,
+		"HGETALL": s.cmdHGetAll,
+	}
+	return s
+}
+
+// ListenAndServe accepts connections on addr and serves them until Accept
+// fails (typically because the listener was closed).
+func (s *RESPServer) ListenAndServe(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serve(conn)
+	}
+}
+
+type respSession struct {
+	bucket string
+	quit   bool
+}
+
+func (s *RESPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	sess := &respSession{bucket: s.buckets[0]}
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		args, err := readRESPCommand(r)
+		if err != nil {
+			var pe respProtocolError
+			if errors.As(err, &pe) {
+				respErrorf("%s", string(pe)).WriteTo(w) // nolint:errcheck
+				w.Flush()                               // nolint:errcheck
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+		reply := s.dispatch(sess, args)
+		if err := reply.WriteTo(w); err != nil {
+			return
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+		if sess.quit {
+			return
+		}
+	}
+}
+
+func (s *RESPServer) dispatch(sess *respSession, args [][]byte) RESPReply {
+	name := strings.ToUpper(string(args[0]))
+	h, ok := s.handlers[name]
+	if !ok {
+		return respErrorf("unknown command '%s'", args[0])
+	}
+	return h(sess, args[1:])
+}
+
+// Limits on the multibulk count and per-argument length readRESPCommand
+// will believe before allocating, so a connection can't claim "*100000000000"
+// or "$100000000000" and make the server try to allocate more memory than
+// the machine has.
+const (
+	maxRESPArgs    = 1024 * 4
+	maxRESPBulkLen = 8 * 1024 * 1024
+)
+
+// respProtocolError is a malformed-input error that's worth reporting back
+// to the client as a RESP error reply before closing the connection,
+// unlike a plain transport/io error from the underlying conn.
+type respProtocolError string
+
+func (e respProtocolError) Error() string { return string(e) }
+
+func readRESPCommand(r *bufio.Reader) ([][]byte, error) {
+	line, err := readRESPLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, nil
+	}
+	if line[0] != '*' {
+		return bytes.Fields(line), nil
+	}
+	n, err := strconv.Atoi(string(line[1:]))
+	if err != nil || n < 0 || n > maxRESPArgs {
+		return nil, respProtocolError("invalid multibulk length")
+	}
+	args := make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		line, err := readRESPLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, respProtocolError("expected bulk string argument")
+		}
+		size, err := strconv.Atoi(string(line[1:]))
+		if err != nil || size < 0 || size > maxRESPBulkLen {
+			return nil, respProtocolError("invalid bulk length")
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, buf[:size])
+	}
+	return args, nil
+}
+
+func readRESPLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(line, "\r\n"), nil
+}
+
+func (s *RESPServer) cmdPing(_ *respSession, args [][]byte) RESPReply {
+	if len(args) == 0 {
+		return respSimpleString("PONG")
+	}
+	return respBulk(args[0])
+}
+
+func (s *RESPServer) cmdSelect(sess *respSession, args [][]byte) RESPReply {
+	if len(args) != 1 {
+		return respErrorf("wrong number of arguments for 'select' command")
+	}
+	idx, err := strconv.Atoi(string(args[0]))
+	if err != nil || idx < 0 || idx >= len(s.buckets) {
+		return respErrorf("DB index is out of range")
+	}
+	sess.bucket = s.buckets[idx]
+	return respSimpleString("OK")
+}
+
+func (s *RESPServer) cmdCommand(_ *respSession, _ [][]byte) RESPReply {
+	return respArrayOf()
+}
+
+func (s *RESPServer) cmdQuit(sess *respSession, _ [][]byte) RESPReply {
+	sess.quit = true
+	return respSimpleString("OK")
+}
+
+func (s *RESPServer) cmdGet(sess *respSession, args [][]byte) RESPReply {
+	if len(args) != 1 {
+		return respErrorf("wrong number of arguments for 'get' command")
+	}
+	var v []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bu := tx.Bucket([]byte(sess.bucket))
+		if bu == nil {
+			return nil
+		}
+		if val := bu.Get(args[0]); val != nil {
+			v = append([]byte(nil), val...)
+		}
+		return nil
+	})
+	if err != nil {
+		return respErrorf("%v", err)
+	}
+	if v == nil {
+		return respNilBulk()
+	}
+	return respBulk(v)
+}
+
+func (s *RESPServer) cmdSet(sess *respSession, args [][]byte) RESPReply {
+	if len(args) != 2 {
+		return respErrorf("wrong number of arguments for 'set' command")
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bu, err := tx.CreateBucketIfNotExists([]byte(sess.bucket))
+		if err != nil {
+			return err
+		}
+		return bu.Put(args[0], args[1])
+	})
+	if err != nil {
+		return respErrorf("%v", err)
+	}
+	return respSimpleString("OK")
+}
+
+func (s *RESPServer) cmdDel(sess *respSession, args [][]byte) RESPReply {
+	if len(args) == 0 {
+		return respErrorf("wrong number of arguments for 'del' command")
+	}
+	var n int64
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bu := tx.Bucket([]byte(sess.bucket))
+		if bu == nil {
+			return nil
+		}
+		for _, k := range args {
+			if bu.Get(k) == nil {
+				continue
+			}
+			n++
+			if err := bu.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return respErrorf("%v", err)
+	}
+	return respInteger(n)
+}
+
+func (s *RESPServer) cmdExists(sess *respSession, args [][]byte) RESPReply {
+	var n int64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bu := tx.Bucket([]byte(sess.bucket))
+		if bu == nil {
+			return nil
+		}
+		for _, k := range args {
+			if bu.Get(k) != nil {
+				n++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return respErrorf("%v", err)
+	}
+	return respInteger(n)
+}
+
+func (s *RESPServer) cmdKeys(sess *respSession, _ [][]byte) RESPReply {
+	var items []RESPReply
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bu := tx.Bucket([]byte(sess.bucket))
+		if bu == nil {
+			return nil
+		}
+		return bu.ForEach(func(k, _ []byte) error {
+			items = append(items, respBulk(append([]byte(nil), k...)))
+			return nil
+		})
+	})
+	if err != nil {
+		return respErrorf("%v", err)
+	}
+	return respArrayOf(items...)
+}
+
+func (s *RESPServer) cmdScan(sess *respSession, args [][]byte) RESPReply {
+	// Cursor-less SCAN: the whole bucket always fits in one reply, with a
+	// cursor of "0" signalling completion, as redis-cli expects.
+	keys := s.cmdKeys(sess, args)
+	return respArrayOf(respBulk([]byte("0")), keys)
+}
+
+func (s *RESPServer) cmdHGet(_ *respSession, args [][]byte) RESPReply {
+	if len(args) != 2 {
+		return respErrorf("wrong number of arguments for 'hget' command")
+	}
+	var v []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bu := tx.Bucket(args[0])
+		if bu == nil {
+			return nil
+		}
+		if val := bu.Get(args[1]); val != nil {
+			v = append([]byte(nil), val...)
+		}
+		return nil
+	})
+	if err != nil {
+		return respErrorf("%v", err)
+	}
+	if v == nil {
+		return respNilBulk()
+	}
+	return respBulk(v)
+}
+
+func (s *RESPServer) cmdHSet(_ *respSession, args [][]byte) RESPReply {
+	if len(args) != 3 {
+		return respErrorf("wrong number of arguments for 'hset' command")
+	}
+	var created int64
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bu, err := tx.CreateBucketIfNotExists(args[0])
+		if err != nil {
+			return err
+		}
+		if bu.Get(args[1]) == nil {
+			created = 1
+		}
+		return bu.Put(args[1], args[2])
+	})
+	if err != nil {
+		return respErrorf("%v", err)
+	}
+	return respInteger(created)
+}
+
+func (s *RESPServer) cmdHDel(_ *respSession, args [][]byte) RESPReply {
+	if len(args) < 2 {
+		return respErrorf("wrong number of arguments for 'hdel' command")
+	}
+	var n int64
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bu := tx.Bucket(args[0])
+		if bu == nil {
+			return nil
+		}
+		for _, f := range args[1:] {
+			if bu.Get(f) == nil {
+				continue
+			}
+			n++
+			if err := bu.Delete(f); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return respErrorf("%v", err)
+	}
+	return respInteger(n)
+}
+
+func (s *RESPServer) cmdHKeys(_ *respSession, args [][]byte) RESPReply {
+	if len(args) != 1 {
+		return respErrorf("wrong number of arguments for 'hkeys' command")
+	}
+	var items []RESPReply
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bu := tx.Bucket(args[0])
+		if bu == nil {
+			return nil
+		}
+		return bu.ForEach(func(k, _ []byte) error {
+			items = append(items, respBulk(append([]byte(nil), k...)))
+			return nil
+		})
+	})
+	if err != nil {
+		return respErrorf("%v", err)
+	}
+	return respArrayOf(items...)
+}
+
+func (s *RESPServer) cmdHGetAll(_ *respSession, args [][]byte) RESPReply {
+	if len(args) != 1 {
+		return respErrorf("wrong number of arguments for 'hgetall' command")
+	}
+	var items []RESPReply
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bu := tx.Bucket(args[0])
+		if bu == nil {
+			return nil
+		}
+		return bu.ForEach(func(k, v []byte) error {
+			items = append(items, respBulk(append([]byte(nil), k...)), respBulk(append([]byte(nil), v...)))
+			return nil
+		})
+	})
+	if err != nil {
+		return respErrorf("%v", err)
+	}
+	return respArrayOf(items...)
+}
@@ -0,0 +1,82 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadRESPCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"inline", "PING\r\n", []string{"PING"}},
+		{"inline multi arg", "SET foo bar\r\n", []string{"SET", "foo", "bar"}},
+		{"multibulk", "*2\r\n$3\r\nGET\r\n$3\r\nfoo\r\n", []string{"GET", "foo"}},
+		{"multibulk empty arg", "*1\r\n$0\r\n\r\n", []string{""}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tt.in))
+			got, err := readRESPCommand(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			for i, w := range tt.want {
+				if string(got[i]) != w {
+					t.Fatalf("got %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadRESPCommandEmptyLine(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("\r\n"))
+	got, err := readRESPCommand(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != nil {
+		t.Fatalf("got %q, want nil", got)
+	}
+}
+
+func TestReadRESPCommandInvalidMultibulkLength(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("*nope\r\n"))
+	if _, err := readRESPCommand(r); err == nil {
+		t.Fatal("expected error for invalid multibulk length")
+	}
+}
+
+func TestReadRESPCommandExpectedBulkString(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("*1\r\n+foo\r\n"))
+	if _, err := readRESPCommand(r); err == nil {
+		t.Fatal("expected error for non-bulk argument")
+	}
+}
+
+func TestReadRESPLine(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("foo\r\nbar\n"))
+	line, err := readRESPLine(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(line, []byte("foo")) {
+		t.Fatalf("got %q, want %q", line, "foo")
+	}
+	line, err = readRESPLine(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(line, []byte("bar")) {
+		t.Fatalf("got %q, want %q", line, "bar")
+	}
+}
@@ -0,0 +1,308 @@
+package command
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+// indexDoc is what gets indexed for each key/value pair of a bucket.
+type indexDoc struct {
+	Key   string
+	Value string
+}
+
+const indexBatchSize = 1000
+
+// indexString renders b for indexing/searching: valid UTF-8 is indexed as
+// literal text, everything else falls back to safeBytesToString (mirrors
+// utf8Encoder.Encode in encoding.go).
+func indexString(b []byte) string {
+	if !utf8.Valid(b) {
+		return safeBytesToString(b)
+	}
+	return string(b)
+}
+
+// Indexer manages on-disk bleve indexes for buckets of a database. Index
+// state lives in a sibling <dbfile>.bleve/<bucket>/ directory so it
+// survives restarts.
+type Indexer struct {
+	dbPath string
+}
+
+// NewIndexer returns an Indexer storing indexes alongside dbPath.
+func NewIndexer(dbPath string) *Indexer {
+	return &Indexer{dbPath: dbPath}
+}
+
+func (ix *Indexer) dir(bucket string) string {
+	return filepath.Join(ix.dbPath+".bleve", bucket)
+}
+
+// Exists reports whether bucket already has an on-disk index.
+func (ix *Indexer) Exists(bucket string) bool {
+	_, err := os.Stat(ix.dir(bucket))
+	return err == nil
+}
+
+func (ix *Indexer) open(bucket string) (bleve.Index, error) {
+	return bleve.Open(ix.dir(bucket))
+}
+
+// Create builds a new index for bucket by streaming it from db under a
+// single read pass. If useValue is true, the field indexed for each key is
+// its value rather than the key itself.
+func (ix *Indexer) Create(db DB, bucket string, useValue bool) (int, error) {
+	if ix.Exists(bucket) {
+		return 0, fmt.Errorf("index for bucket %q already exists", bucket)
+	}
+	idx, err := bleve.New(ix.dir(bucket), bleve.NewIndexMapping())
+	if err != nil {
+		return 0, err
+	}
+	defer idx.Close()
+	return ix.fill(db, idx, bucket, useValue)
+}
+
+func (ix *Indexer) fill(db DB, idx bleve.Index, bucket string, useValue bool) (int, error) {
+	var n int
+	batch := idx.NewBatch()
+	err := db.ListKeys([]string{bucket}, true, func(k, v []byte) error {
+		field := indexString(k)
+		if useValue {
+			field = indexString(v)
+		}
+		if err := batch.Index(hex.EncodeToString(k), indexDoc{Key: indexString(k), Value: field}); err != nil {
+			return err
+		}
+		n++
+		if batch.Size() < indexBatchSize {
+			return nil
+		}
+		if err := idx.Batch(batch); err != nil {
+			return err
+		}
+		batch = idx.NewBatch()
+		return nil
+	})
+	if err != nil {
+		return n, err
+	}
+	if batch.Size() > 0 {
+		err = idx.Batch(batch)
+	}
+	return n, err
+}
+
+// Drop removes the on-disk index for bucket.
+func (ix *Indexer) Drop(bucket string) error {
+	if !ix.Exists(bucket) {
+		return fmt.Errorf("no index for bucket %q", bucket)
+	}
+	return os.RemoveAll(ix.dir(bucket))
+}
+
+// Reindex rebuilds the index for bucket from scratch.
+func (ix *Indexer) Reindex(db DB, bucket string, useValue bool) (int, error) {
+	if ix.Exists(bucket) {
+		if err := ix.Drop(bucket); err != nil {
+			return 0, err
+		}
+	}
+	return ix.Create(db, bucket, useValue)
+}
+
+// OnPut keeps bucket's index (if any) current after a key is written. Write
+// commands call this so indexes don't drift out of sync between reindexes.
+func (ix *Indexer) OnPut(bucket string, key, value []byte) error {
+	if !ix.Exists(bucket) {
+		return nil
+	}
+	idx, err := ix.open(bucket)
+	if err != nil {
+		return err
+	}
+	defer idx.Close()
+	return idx.Index(hex.EncodeToString(key), indexDoc{Key: indexString(key), Value: indexString(value)})
+}
+
+// OnDelete keeps bucket's index (if any) current after a key is removed.
+func (ix *Indexer) OnDelete(bucket string, key []byte) error {
+	if !ix.Exists(bucket) {
+		return nil
+	}
+	idx, err := ix.open(bucket)
+	if err != nil {
+		return err
+	}
+	defer idx.Close()
+	return idx.Delete(hex.EncodeToString(key))
+}
+
+// Search runs a bleve query string against bucket's index and streams hits
+// to fn in descending score order.
+func (ix *Indexer) Search(bucket, q string, fn func(id string, score float64, fields map[string]interface{}) error) error {
+	idx, err := ix.open(bucket)
+	if err != nil {
+		return err
+	}
+	defer idx.Close()
+	req := bleve.NewSearchRequest(bleve.NewQueryStringQuery(q))
+	req.Fields = []string{"Key", "Value"}
+	req.Size = maxRows
+	res, err := idx.Search(req)
+	if err != nil {
+		return err
+	}
+	for _, hit := range res.Hits {
+		if err := fn(hit.ID, hit.Score, hit.Fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyIndexPut keeps bucket's on-disk index (if any) in sync after a
+// write applied via the put/rename commands. ix is nil when the caller
+// wasn't given an Indexer (e.g. operating on a --remote database), and
+// indexes only cover top-level buckets, so both cases are a no-op.
+func notifyIndexPut(ix *Indexer, bucket []string, key, value []byte) error {
+	if ix == nil || len(bucket) != 1 {
+		return nil
+	}
+	return ix.OnPut(bucket[0], key, value)
+}
+
+// notifyIndexDelete is notifyIndexPut's counterpart for the del/rename
+// commands.
+func notifyIndexDelete(ix *Indexer, bucket []string, key []byte) error {
+	if ix == nil || len(bucket) != 1 {
+		return nil
+	}
+	return ix.OnDelete(bucket[0], key)
+}
+
+// RegisterIndexCommands adds the index, reindex and search verbs to r,
+// backed by ix. db is used to stream bucket contents into new indexes.
+func RegisterIndexCommands(r Register, db DB, ix *Indexer) {
+	r.Register(&indexCommand{indexer: ix, db: db})
+	r.Register(&reindexCommand{indexer: ix, db: db})
+	r.Register(&searchCommand{indexer: ix})
+}
+
+type indexCommand struct {
+	indexer *Indexer
+	db      DB
+}
+
+func (c *indexCommand) Alias() []string { return []string{"index"} }
+
+func (c *indexCommand) Help() string {
+	return "Manage full-text indexes: 'index create <bucket> [--value|--key]' or 'index drop <bucket>'"
+}
+
+func (c *indexCommand) ReadOnly() bool { return true }
+
+func (c *indexCommand) Check(ctx *Context, args []string) error {
+	v := NewValidats().MinArgs(2).MaxArgs(3).Choices(0, []string{"create", "drop"})
+	if err := v.Finish()(ctx, args); err != nil {
+		return err
+	}
+	if args[0] == "create" {
+		return NewValidats().Choices(2, []string{"--value", "--key"}).Finish()(ctx, args)
+	}
+	return nil
+}
+
+func (c *indexCommand) Execute(ctx *Context, args []string) error {
+	bucket := args[1]
+	switch args[0] {
+	case "create":
+		useValue := len(args) > 2 && args[2] == "--value"
+		n, err := c.indexer.Create(c.db, bucket, useValue)
+		if err != nil {
+			return err
+		}
+		ctx.Printf("indexed %d documents\n", n)
+	case "drop":
+		if err := c.indexer.Drop(bucket); err != nil {
+			return err
+		}
+		ctx.Printf("dropped index for bucket %s\n", bucket)
+	}
+	return nil
+}
+
+type reindexCommand struct {
+	indexer *Indexer
+	db      DB
+}
+
+func (c *reindexCommand) Alias() []string { return []string{"reindex"} }
+
+func (c *reindexCommand) Help() string {
+	return "Rebuild the full-text index for a bucket: reindex <bucket> [--value|--key]"
+}
+
+func (c *reindexCommand) ReadOnly() bool { return true }
+
+func (c *reindexCommand) Check(ctx *Context, args []string) error {
+	return NewValidats().MinArgs(1).MaxArgs(2).Choices(1, []string{"--value", "--key"}).Finish()(ctx, args)
+}
+
+func (c *reindexCommand) Execute(ctx *Context, args []string) error {
+	useValue := len(args) > 1 && args[1] == "--value"
+	n, err := c.indexer.Reindex(c.db, args[0], useValue)
+	if err != nil {
+		return err
+	}
+	ctx.Printf("reindexed %d documents\n", n)
+	return nil
+}
+
+type searchCommand struct {
+	indexer *Indexer
+}
+
+func (c *searchCommand) Alias() []string { return []string{"search"} }
+
+func (c *searchCommand) Help() string {
+	return "Search a bucket's full-text index: search <bucket> <query>"
+}
+
+func (c *searchCommand) ReadOnly() bool { return true }
+
+func (c *searchCommand) Check(ctx *Context, args []string) error {
+	return NewValidats().MinArgs(2).Finish()(ctx, args)
+}
+
+const searchSnippetLen = 80
+
+func (c *searchCommand) Execute(ctx *Context, args []string) error {
+	bucket := args[0]
+	q := strings.Join(args[1:], " ")
+	tl := newTablePrinter([]string{"key", "score", "snippet"})
+	err := c.indexer.Search(bucket, q, func(id string, score float64, fields map[string]interface{}) error {
+		key, _ := fields["Key"].(string)
+		value, _ := fields["Value"].(string)
+		snippet := value
+		if len(snippet) > searchSnippetLen {
+			snippet = snippet[:searchSnippetLen] + "..."
+		}
+		if !tl.add(ctx, []string{key, fmt.Sprintf("%.4f", score), snippet}) {
+			return errExit
+		}
+		return nil
+	})
+	tl.out(ctx)
+	if err == errExit { // nolint:errorlint
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,24 @@
+package command
+
+import "testing"
+
+func TestIndexString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+	}{
+		{"ascii", []byte("hello"), "hello"},
+		{"utf8", []byte("中文English"), "中文English"},
+		{"invalid utf8", []byte{0xff, 0xfe}, safeBytesToString([]byte{0xff, 0xfe})},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := indexString(tt.in)
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
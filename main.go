@@ -4,12 +4,21 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/ko-han/boltdb-cli/command"
 	"go.etcd.io/bbolt"
+	"google.golang.org/grpc"
+)
+
+var (
+	serveRESP   = flag.String("serve-resp", "", "start a RESP (redis protocol) server on the given address (e.g. :6380) instead of the REPL")
+	respBuckets = flag.String("resp-buckets", "default", "comma-separated bucket names selectable via the RESP SELECT command, in index order")
+	serveGRPC   = flag.String("serve-grpc", "", "start a gRPC server on the given address (e.g. :6381) exposing the database instead of the REPL")
+	remote      = flag.String("remote", "", "operate on a remote database exposed by --serve-grpc (e.g. grpc://host:port) instead of <database-filename>")
 )
 
 func init() {
@@ -22,6 +31,11 @@ func init() {
 func main() {
 	flag.Parse()
 
+	if *remote != "" {
+		runRemote()
+		return
+	}
+
 	filename := flag.Arg(0)
 	if filename == "" {
 		flag.Usage()
@@ -40,7 +54,31 @@ func main() {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	r := command.NewRegisterWithDB(db)
+	cdb := command.NewLocalDB(db)
+
+	if *serveRESP != "" {
+		srv := command.NewRESPServer(db, strings.Split(*respBuckets, ","))
+		fmt.Printf("serving RESP on %s\n", *serveRESP)
+		if err := srv.ListenAndServe(*serveRESP); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *serveGRPC != "" {
+		if err := serveGRPCDB(cdb, *serveGRPC); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	ix := command.NewIndexer(filename)
+	r := command.NewRegisterWithDB(cdb, ix)
+	command.RegisterIndexCommands(r, cdb, ix)
+	command.RegisterIOCommands(r, cdb)
+	command.RegisterSnapshotCommand(r, db)
 	ctx := command.NewContext()
 	defer ctx.Close()
 
@@ -68,3 +106,48 @@ func main() {
 		ctx.Do(r)
 	}
 }
+
+// runRemote runs the CLI against a database exposed by another boltdb-cli
+// process via --serve-grpc, instead of opening a local file.
+func runRemote() {
+	addr := strings.TrimPrefix(*remote, "grpc://")
+	cdb, err := command.NewRemoteDB(addr)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	r := command.NewRegisterWithDB(cdb, nil)
+	command.RegisterIOCommands(r, cdb)
+	ctx := command.NewContext()
+	defer ctx.Close()
+
+	promptMode := flag.NArg() == 0
+	if !promptMode {
+		if err := r.Execute(ctx, strings.Join(flag.Args(), " ")); err != nil {
+			ctx.Fatalf("error: %v\n", err)
+			ctx.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
+	ctx.SetPrompt(*remote + " >> ")
+	for ctx.Next() {
+		ctx.Do(r)
+	}
+}
+
+// serveGRPCDB starts a gRPC server exposing db on addr, blocking until it
+// stops serving.
+func serveGRPCDB(db command.DB, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	s := grpc.NewServer()
+	command.NewRemoteDBServer(db).Register(s)
+	fmt.Printf("serving gRPC on %s\n", addr)
+	return s.Serve(ln)
+}